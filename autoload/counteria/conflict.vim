@@ -0,0 +1,18 @@
+
+" open the stored and the written lines side by side in diff mode
+function! counteria#conflict#open(name, stored, written) abort
+    tabnew
+    call s:scratch(a:name . ' (stored)', a:stored)
+    rightbelow vnew
+    call s:scratch(a:name . ' (written)', a:written)
+    wincmd p
+endfunction
+
+function! s:scratch(name, lines) abort
+    setlocal buftype=nofile bufhidden=wipe noswapfile
+    call setline(1, a:lines)
+    setlocal nomodified filetype=json
+    let b:counteria_conflict_name = a:name
+    setlocal statusline=%{b:counteria_conflict_name}
+    diffthis
+endfunction
@@ -15,6 +15,21 @@ function! counteria#job#new() abort
         if exists('g:counteria_data_path')
             call add(cmd, '-data=' . fnameescape(g:counteria_data_path))
         endif
+        if exists('g:counteria_datastore')
+            call add(cmd, '-datastore=' . g:counteria_datastore)
+        endif
+        if exists('g:counteria_trash_days')
+            call add(cmd, '-trash-days=' . g:counteria_trash_days)
+        endif
+        if exists('g:counteria_profile')
+            call add(cmd, '-profile=' . g:counteria_profile)
+        endif
+        if exists('g:counteria_profile_dir')
+            call add(cmd, '-profile-dir=' . fnameescape(g:counteria_profile_dir))
+        endif
+        for [name, path] in items(get(g:, 'counteria_attach', {}))
+            call add(cmd, '-attach=' . name . '=' . fnameescape(path))
+        endfor
 
         let id = jobstart(cmd, {
             \ 'rpc': v:true,
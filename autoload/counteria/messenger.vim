@@ -15,6 +15,10 @@ function! counteria#messenger#set_func(func) abort
     let s:func = { message -> a:func(message) }
 endfunction
 
+function! counteria#messenger#info(message) abort
+    call s:func('[counteria] ' . a:message)
+endfunction
+
 function! counteria#messenger#warn(message) abort
     echohl WarningMsg
     call s:func('[counteria] ' . a:message)
@@ -26,3 +30,7 @@ function! counteria#messenger#error(message) abort
     call s:func('[counteria] ' . a:message)
     echohl None
 endfunction
+
+function! counteria#messenger#confirm(message, choices) abort
+    return confirm('[counteria] ' . a:message, join(a:choices, "\n"))
+endfunction
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/pkg/errors"
+)
+
+// Attachment : a named sqlite file listed with the tasks of the profile
+type Attachment struct {
+	Name string
+	Path string
+}
+
+// Attachments : flag value, `-attach name=path` can be repeated
+type Attachments []Attachment
+
+func (attachments *Attachments) String() string {
+	pairs := make([]string, len(*attachments))
+	for i, a := range *attachments {
+		pairs[i] = fmt.Sprintf("%s=%s", a.Name, a.Path)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set :
+func (attachments *Attachments) Set(value string) error {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+		return errors.Errorf("invalid attachment: %s (expected name=path)", value)
+	}
+	for _, a := range *attachments {
+		if a.Name == kv[0] {
+			return errors.Errorf("duplicated attachment name: %s", kv[0])
+		}
+	}
+	*attachments = append(*attachments, Attachment{Name: kv[0], Path: kv[1]})
+	return nil
+}
+
+// AttachedSources : keeps the opened attachments to share them between the profiles
+type AttachedSources struct {
+	Open func(attachment Attachment) (*domain.Dep, error)
+
+	mu   sync.Mutex
+	deps map[string]*domain.Dep
+}
+
+// Get : open the attachment at the first time
+func (sources *AttachedSources) Get(attachment Attachment) (*domain.Dep, error) {
+	sources.mu.Lock()
+	defer sources.mu.Unlock()
+
+	if dep, ok := sources.deps[attachment.Name]; ok {
+		return dep, nil
+	}
+
+	dep, err := sources.Open(attachment)
+	if err != nil {
+		return nil, errors.Wrap(err, attachment.Name)
+	}
+	if sources.deps == nil {
+		sources.deps = make(map[string]*domain.Dep)
+	}
+	sources.deps[attachment.Name] = dep
+	return dep, nil
+}
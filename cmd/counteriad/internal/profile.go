@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/pkg/errors"
+)
+
+// Profiles : keeps the opened profiles to switch back without reopening
+type Profiles struct {
+	Open func(name string) (*domain.Dep, error)
+
+	mu   sync.Mutex
+	deps map[string]*domain.Dep
+}
+
+// Get : open the profile at the first time
+func (profiles *Profiles) Get(name string) (*domain.Dep, error) {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+
+	if dep, ok := profiles.deps[name]; ok {
+		return dep, nil
+	}
+
+	dep, err := profiles.Open(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if profiles.deps == nil {
+		profiles.deps = make(map[string]*domain.Dep)
+	}
+	profiles.deps[name] = dep
+	return dep, nil
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/notomo/counteria.nvim/src/command/datacmd"
+	"github.com/pkg/errors"
+)
+
+// Subcommand : `counteriad [flags] {name} [args]` runs without vim
+type Subcommand struct {
+	DataCmd *datacmd.Command
+	Stdin   io.Reader
+	Stdout  io.Writer
+}
+
+// Run :
+func (sub *Subcommand) Run(args []string) error {
+	name, args := args[0], args[1:]
+	switch name {
+	case "export":
+		return sub.output(args, sub.DataCmd.Export)
+	case "export-calendar":
+		return sub.output(args, sub.DataCmd.ExportCalendar)
+	case "export-history":
+		return sub.exportHistory(args)
+	case "import":
+		return sub.importData(args)
+	case "sync":
+		return sub.sync(args)
+	}
+	return errors.Errorf("unknown subcommand: %s", name)
+}
+
+// `export [path]`, `export-calendar [path]` : to stdout if no path
+func (sub *Subcommand) output(args []string, export func(io.Writer) error) error {
+	if len(args) == 0 || args[0] == "-" {
+		return export(sub.Stdout)
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := export(f); err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// `export-history [-from=yyyy-mm-dd] [-to=yyyy-mm-dd] [-task=id,...] [path]`
+func (sub *Subcommand) exportHistory(args []string) error {
+	flags := flag.NewFlagSet("export-history", flag.ContinueOnError)
+	from := flags.String("from", "", "the first done date")
+	to := flags.String("to", "", "the last done date")
+	tasks := flags.String("task", "", "comma separated task ids")
+	if err := flags.Parse(args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	filter, err := datacmd.ParseHistoryFilter(*from, *to, *tasks)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return sub.output(flags.Args(), func(w io.Writer) error {
+		return sub.DataCmd.ExportHistory(w, filter)
+	})
+}
+
+// `import [-mode=merge|replace] [path]` : from stdin if no path
+func (sub *Subcommand) importData(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := flags.String("mode", string(datacmd.ImportModeMerge), fmt.Sprint(datacmd.ImportModes()))
+	if err := flags.Parse(args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	reader := sub.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	result, err := sub.DataCmd.Import(reader, datacmd.ImportMode(*mode))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := fmt.Fprintln(sub.Stdout, result); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// `sync {path}` : with the other database file
+func (sub *Subcommand) sync(args []string) error {
+	if len(args) == 0 {
+		return errors.New("sync requires a database path")
+	}
+
+	result, err := sub.DataCmd.Sync(args[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := fmt.Fprintln(sub.Stdout, result); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, conflict := range result.Conflicts {
+		if _, err := fmt.Fprintln(sub.Stdout, "conflict:", conflict); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
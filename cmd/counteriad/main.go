@@ -5,14 +5,23 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/adrg/xdg"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
 
 	"github.com/notomo/counteria.nvim/cmd/counteriad/internal"
 	"github.com/notomo/counteria.nvim/src/command"
+	"github.com/notomo/counteria.nvim/src/datastore/aggregateimpl"
+	"github.com/notomo/counteria.nvim/src/datastore/fileimpl"
+	"github.com/notomo/counteria.nvim/src/datastore/memoryimpl"
 	"github.com/notomo/counteria.nvim/src/datastore/sqliteimpl"
+	"github.com/notomo/counteria.nvim/src/datastore/sqliteimpl/database"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
 	"github.com/notomo/counteria.nvim/src/lib"
 	"github.com/notomo/counteria.nvim/src/router"
 	"github.com/notomo/counteria.nvim/src/router/route"
@@ -20,19 +29,60 @@ import (
 	"github.com/notomo/counteria.nvim/src/vimlib"
 )
 
-var dataPath string
+var (
+	dataPath    string
+	datastore   string
+	backupCount int
+	trashDays   int
+	profile     string
+	profileDir  string
+	attachments internal.Attachments
+
+	attachedSources = &internal.AttachedSources{Open: openAttached}
+)
 
 func init() {
-	flag.StringVar(&dataPath, "data", "", "datastore file path")
+	flag.StringVar(&dataPath, "data", "", "datastore file path (directory path for file datastore)")
+	flag.StringVar(&datastore, "datastore", "sqlite", "datastore type: sqlite, memory, file")
+	flag.IntVar(&backupCount, "backup-count", database.DefaultBackupCount, "the number of sqlite backups to keep per reason")
+	flag.IntVar(&trashDays, "trash-days", 30, "the days to keep trashed tasks, 0 to keep forever")
+	flag.StringVar(&profile, "profile", command.DefaultProfile, "the profile to use at start, the default one uses the data path")
+	flag.Var(&attachments, "attach", "name=path of a sqlite file to list with the tasks, can be repeated")
+	flag.StringVar(&profileDir, "profile-dir", "", "the directory of the named profiles (default: counteria/profiles in the xdg data directory)")
 }
 
 func main() {
 	flag.Parse()
+	if args := flag.Args(); len(args) != 0 {
+		if err := runSubcommand(args); err != nil {
+			fmt.Fprintf(os.Stderr, "%+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		panic(fmt.Sprintf("%+v", err))
 	}
 }
 
+func runSubcommand(args []string) error {
+	root := &command.RootCommand{
+		Clock:       lib.NewClock(),
+		OpenProfile: setupDep,
+	}
+	if err := root.SwitchProfile(profile); err != nil {
+		return errors.WithStack(err)
+	}
+
+	sub := &internal.Subcommand{
+		DataCmd: root.DataCmd(),
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+	}
+	return sub.Run(args)
+}
+
 func run() error {
 	reader := os.Stdin
 	writer := os.Stdout
@@ -53,26 +103,21 @@ func run() error {
 		return errors.WithStack(err)
 	}
 
-	dep, err := sqliteimpl.Setup(
-		sqliteimpl.WithDataPath(dataPath),
-	)
-	if err != nil {
+	profiles := &internal.Profiles{Open: openProfile}
+
+	bufClientFactory := &vimlib.BufferClientFactory{Vim: vim}
+	root := &command.RootCommand{
+		Renderer:            &view.Renderer{Vim: vim},
+		BufferClientFactory: bufClientFactory,
+		Redirector:          &route.Redirector{Vim: vim, BufferClientFactory: bufClientFactory},
+		Clock:               lib.NewClock(),
+		OpenProfile:         profiles.Get,
+	}
+	if err := root.SwitchProfile(profile); err != nil {
 		return errors.WithStack(err)
 	}
 
-	bufClientFactory := &vimlib.BufferClientFactory{Vim: vim}
-	handler := internal.NewHandler(
-		router.New(
-			vim,
-			&command.RootCommand{
-				Renderer:            &view.Renderer{Vim: vim},
-				BufferClientFactory: bufClientFactory,
-				Redirector:          &route.Redirector{Vim: vim, BufferClientFactory: bufClientFactory},
-				Clock:               lib.NewClock(),
-				Dep:                 dep,
-			},
-		),
-	)
+	handler := internal.NewHandler(router.New(vim, root))
 
 	handles := []struct {
 		method string
@@ -99,3 +144,94 @@ func run() error {
 	}
 	return nil
 }
+
+// openProfile : back up and purge the old trashed tasks on opening
+// NOTE: only the primary source is backed up and purged, the attached sources are left to their owners.
+func openProfile(name string) (*domain.Dep, error) {
+	dep, err := setupDep(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err := dep.BackupRepository.Backup(model.BackupReasonStart); err != nil && errors.Cause(err) != domain.ErrNotSupported {
+		return nil, errors.WithStack(err)
+	}
+
+	if trashDays > 0 {
+		dataCmd := (&command.RootCommand{Clock: lib.NewClock(), Dep: dep}).DataCmd()
+		if _, err := dataCmd.PurgeTrash(time.Duration(trashDays) * 24 * time.Hour); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return dep, nil
+}
+
+// setupDep : aggregated with the attached sqlite files if exist
+// NOTE: the attached sources are shared between the profiles.
+func setupDep(name string) (*domain.Dep, error) {
+	dep, err := setupPrimaryDep(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(attachments) == 0 {
+		return dep, nil
+	}
+
+	sources := []aggregateimpl.Source{{Name: name, Dep: dep}}
+	for _, a := range attachments {
+		attached, err := attachedSources.Get(a)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		sources = append(sources, aggregateimpl.Source{Name: a.Name, Dep: attached})
+	}
+	return aggregateimpl.Setup(sources...), nil
+}
+
+func openAttached(attachment internal.Attachment) (*domain.Dep, error) {
+	return sqliteimpl.Setup(
+		sqliteimpl.WithDataPath(attachment.Path),
+		sqliteimpl.WithBackupCount(backupCount),
+	)
+}
+
+func setupPrimaryDep(name string) (*domain.Dep, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch datastore {
+	case "sqlite":
+		return sqliteimpl.Setup(
+			sqliteimpl.WithDataPath(path),
+			sqliteimpl.WithBackupCount(backupCount),
+		)
+	case "memory":
+		return memoryimpl.Setup(), nil
+	case "file":
+		return fileimpl.Setup(
+			fileimpl.WithDirPath(path),
+		)
+	}
+	return nil, errors.Errorf("invalid datastore: %s", datastore)
+}
+
+// profilePath : the data path option for the default profile, otherwise in the profile directory
+func profilePath(name string) (string, error) {
+	if name == command.DefaultProfile {
+		return dataPath, nil
+	}
+
+	dir := profileDir
+	if dir == "" {
+		dir = filepath.Join(xdg.DataHome, "counteria", "profiles")
+	}
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if datastore == "sqlite" {
+		return filepath.Join(dir, name+".db"), nil
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -9,7 +9,7 @@ require (
 	github.com/go-sql-driver/mysql v1.5.0 // indirect
 	github.com/kyoh86/scopelint v0.2.0
 	github.com/lib/pq v1.4.0 // indirect
-	github.com/mattn/go-runewidth v0.0.9 // indirect
+	github.com/mattn/go-runewidth v0.0.9
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible
 	github.com/neovim/go-client v1.1.0
 	github.com/notomo/swityp v0.0.0-20200422000112-cbd69bbe7bbf
@@ -0,0 +1,69 @@
+package backupcmd
+
+import (
+	"fmt"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/view"
+	"github.com/pkg/errors"
+)
+
+// Command :
+type Command struct {
+	Renderer   *view.BufferRenderer
+	Redirector *route.Redirector
+
+	BackupRepository repository.BackupRepository
+}
+
+// List : newest first
+func (cmd *Command) List() error {
+	backups, err := cmd.BackupRepository.List()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return cmd.Renderer.BackupList(backups)
+}
+
+// Restore : after confirmation
+func (cmd *Command) Restore(backupID int) error {
+	backup, err := cmd.one(backupID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	at := backup.At.Format("2006-01-02 15:04:05")
+	msg := fmt.Sprintf("restore the backup at %s (%s)? the current data is backed up before restoring.", at, backup.Reason)
+	choice, err := cmd.Renderer.Choose(msg, "&Yes", "&No")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if choice != 0 {
+		return nil
+	}
+
+	if err := cmd.BackupRepository.Restore(backupID); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := cmd.Renderer.Info("restored: " + at); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return cmd.Redirector.ToBackups()
+}
+
+func (cmd *Command) one(backupID int) (*model.Backup, error) {
+	backups, err := cmd.BackupRepository.List()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, backup := range backups {
+		if backup.ID == backupID {
+			return &backup, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
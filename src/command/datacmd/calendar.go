@@ -0,0 +1,208 @@
+package datacmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	calendarProductID = "-//notomo//counteria.nvim//EN"
+	calendarUIDDomain = "counteria.nvim"
+)
+
+// ExportCalendar : upcoming deadlines and completed occurrences as iCalendar
+// NOTE: recurring rules are exported as a recurring event from the next due time.
+// The others are exported as todos for each due time.
+func (cmd *Command) ExportCalendar(w io.Writer) error {
+	now := cmd.Clock.Now()
+	tasks, err := cmd.TaskRepository.List(allByName, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	writer := newICalWriter(w)
+	writer.begin("VCALENDAR")
+	writer.raw("VERSION", "2.0")
+	writer.raw("PRODID", calendarProductID)
+	writer.raw("CALSCALE", "GREGORIAN")
+	for _, task := range tasks {
+		history, err := cmd.TaskRepository.History(task.ID())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		calendarTask{Task: task, history: history, now: now}.write(writer)
+	}
+	writer.end("VCALENDAR")
+
+	if err := writer.flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// explicit todos are exported until this years later
+const calendarHorizonYears = 1
+
+type calendarTask struct {
+	model.Task
+	history []model.DoneTask
+	now     time.Time
+}
+
+func (task calendarTask) write(writer *icalWriter) {
+	for _, done := range task.history {
+		task.writeCompleted(writer, done)
+	}
+
+	rule := task.Rule()
+	if rule.Type() == model.TaskRuleTypeNone {
+		return
+	}
+
+	from := task.StartAt()
+	if len(task.history) != 0 {
+		lastDone := &task.history[len(task.history)-1]
+		from = rule.FulfilledTime(task.StartAt(), lastDone)
+		if rule.Type() == model.TaskRuleTypePeriodic {
+			// NOTE: periodic rule is restarted from the done.
+			from = lastDone.Fulfilled()
+		}
+	}
+
+	if rrule, ok := task.rrule(); ok {
+		first := rule.Occurrences(from).Next()
+		if first == nil {
+			return
+		}
+		task.writeRecurring(writer, *first, rrule)
+		return
+	}
+
+	to := task.now.AddDate(calendarHorizonYears, 0, 0)
+	for _, at := range rule.Occurrences(from).Between(from, to) {
+		task.writeTodo(writer, at)
+	}
+}
+
+// rrule : false if the rule can not be a recurrence rule as is
+func (task calendarTask) rrule() (string, bool) {
+	rule := task.Rule()
+	typ := rule.Type()
+	switch typ {
+	case model.TaskRuleTypePeriodic:
+		period := rule.Periods()[0]
+		return fmt.Sprintf("FREQ=%s;INTERVAL=%d", rruleFrequencies[period.Unit()], period.Number()), true
+	case model.TaskRuleTypeInWeekdays:
+		weekdays := append(model.Weekdays{}, rule.Weekdays()...)
+		sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+		values := []string{}
+		for _, weekday := range weekdays {
+			values = append(values, rruleWeekdays[time.Weekday(weekday)])
+		}
+		return "FREQ=WEEKLY;BYDAY=" + strings.Join(values, ","), true
+	case model.TaskRuleTypeInDaysEveryMonth:
+		days := append(model.Days{}, rule.Days()...)
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		values := []string{}
+		for _, day := range days {
+			// NOTE: BYMONTHDAY skips the months not having the day, but the rule uses the last day instead.
+			if day > 28 {
+				return "", false
+			}
+			values = append(values, strconv.Itoa(int(day)))
+		}
+		return "FREQ=MONTHLY;BYMONTHDAY=" + strings.Join(values, ","), true
+	case model.TaskRuleTypeByTimes, model.TaskRuleTypeInDates, model.TaskRuleTypeNone:
+		return "", false
+	}
+	panic("unreachable: invalid rule type: " + typ)
+}
+
+var rruleFrequencies = map[model.PeriodUnit]string{
+	model.PeriodUnitYear:  "YEARLY",
+	model.PeriodUnitMonth: "MONTHLY",
+	model.PeriodUnitWeek:  "WEEKLY",
+	model.PeriodUnitDay:   "DAILY",
+}
+
+var rruleWeekdays = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// allDay : the due time is the end of a day
+func (task calendarTask) allDay() bool {
+	typ := task.Rule().Type()
+	switch typ {
+	case model.TaskRuleTypeInDates, model.TaskRuleTypeInDaysEveryMonth, model.TaskRuleTypeInWeekdays:
+		return true
+	case model.TaskRuleTypePeriodic, model.TaskRuleTypeByTimes, model.TaskRuleTypeNone:
+		return false
+	}
+	panic("unreachable: invalid rule type: " + typ)
+}
+
+func (task calendarTask) due(writer *icalWriter, name string, at time.Time) {
+	if task.allDay() {
+		writer.date(name, at)
+		return
+	}
+	writer.dateTime(name, at)
+}
+
+func (task calendarTask) uid(suffix string) string {
+	return fmt.Sprintf("task-%d%s@%s", task.ID(), suffix, calendarUIDDomain)
+}
+
+func (task calendarTask) writeRecurring(writer *icalWriter, first time.Time, rrule string) {
+	writer.begin("VEVENT")
+	writer.raw("UID", task.uid(""))
+	writer.dateTime("DTSTAMP", task.now)
+	task.due(writer, "DTSTART", first)
+	writer.raw("RRULE", rrule)
+	writer.text("SUMMARY", task.Name())
+	task.writeDescription(writer)
+	writer.end("VEVENT")
+}
+
+func (task calendarTask) writeTodo(writer *icalWriter, at time.Time) {
+	writer.begin("VTODO")
+	writer.raw("UID", task.uid("-"+at.UTC().Format(icalDateTimeFormat)))
+	writer.dateTime("DTSTAMP", task.now)
+	task.due(writer, "DUE", at)
+	writer.text("SUMMARY", task.Name())
+	task.writeDescription(writer)
+	writer.raw("STATUS", "NEEDS-ACTION")
+	writer.end("VTODO")
+}
+
+func (task calendarTask) writeCompleted(writer *icalWriter, done model.DoneTask) {
+	writer.begin("VTODO")
+	writer.raw("UID", task.uid("-done-"+done.At().UTC().Format(icalDateTimeFormat)))
+	writer.dateTime("DTSTAMP", task.now)
+	if task.Rule().Type() != model.TaskRuleTypeNone {
+		task.due(writer, "DUE", task.Rule().FulfilledTime(task.StartAt(), &done))
+	}
+	writer.text("SUMMARY", done.Name())
+	writer.raw("STATUS", "COMPLETED")
+	writer.dateTime("COMPLETED", done.At())
+	writer.end("VTODO")
+}
+
+func (task calendarTask) writeDescription(writer *icalWriter) {
+	if notes := task.Notes(); notes != "" {
+		writer.text("DESCRIPTION", notes)
+	}
+}
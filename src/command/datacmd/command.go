@@ -0,0 +1,241 @@
+package datacmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/lib"
+	"github.com/pkg/errors"
+)
+
+// Command : data transfer without vim
+type Command struct {
+	Clock lib.Clock
+
+	TaskRepository     repository.TaskRepository
+	TransactionFactory repository.TransactionFactory
+	BackupRepository   repository.BackupRepository
+	SyncRepository     repository.SyncRepository
+}
+
+var allByName = repository.ListOption{
+	Sort: repository.Sort{
+		By:    repository.SortByTaskName,
+		Order: repository.SortOrderAsc,
+	},
+}
+
+// Export : all tasks as json document
+func (cmd *Command) Export(w io.Writer) error {
+	now := cmd.Clock.Now()
+	tasks, err := cmd.TaskRepository.List(allByName, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	doc := Document{
+		Version:    DocumentVersion,
+		ExportedAt: now,
+		Tasks:      []TaskDocument{},
+	}
+	for _, task := range tasks {
+		history, err := cmd.TaskRepository.History(task.ID())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		doc.Tasks = append(doc.Tasks, newTaskDocument(task, history))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ImportMode :
+type ImportMode string
+
+var (
+	// ImportModeMerge : update the tasks having the same name and add the others
+	ImportModeMerge = ImportMode("merge")
+	// ImportModeReplace : purge all tasks including the trashed before importing, not moved to the trash
+	ImportModeReplace = ImportMode("replace")
+)
+
+// ImportModes :
+func ImportModes() []ImportMode {
+	return []ImportMode{ImportModeMerge, ImportModeReplace}
+}
+
+// ImportResult :
+type ImportResult struct {
+	Created int
+	Updated int
+	Deleted int
+	Dones   int
+}
+
+func (result ImportResult) String() string {
+	return fmt.Sprintf("created: %d, updated: %d, deleted: %d, dones: %d", result.Created, result.Updated, result.Deleted, result.Dones)
+}
+
+// Import : in a transaction, after backing up if replacing
+func (cmd *Command) Import(r io.Reader, mode ImportMode) (*ImportResult, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if doc.Version < 1 || DocumentVersion < doc.Version {
+		return nil, errors.Errorf("unsupported document version: %d", doc.Version)
+	}
+
+	for i := range doc.Tasks {
+		task := model.Task{TaskData: &doc.Tasks[i]}
+		if err := task.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "task: %s", task.Name())
+		}
+	}
+
+	now := cmd.Clock.Now()
+	existings, err := cmd.TaskRepository.List(allByName, now)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	trashed := []model.TrashedTask{}
+	if mode == ImportModeReplace {
+		// NOTE: replaced without the backup if the datastore does not support it
+		if _, err := cmd.BackupRepository.Backup(model.BackupReasonImport); err != nil && errors.Cause(err) != domain.ErrNotSupported {
+			return nil, errors.WithStack(err)
+		}
+		trashed, err = cmd.TaskRepository.Trash()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var result *ImportResult
+	if err := cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		r, err := cmd.importDocument(transaction, doc, existings, trashed, mode, now)
+		result = r
+		return err
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result, nil
+}
+
+func (cmd *Command) importDocument(transaction repository.Transaction, doc Document, existings []model.Task, trashed []model.TrashedTask, mode ImportMode, now time.Time) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	matches := taskMatches{}
+	switch mode {
+	case ImportModeMerge:
+		for _, task := range existings {
+			matches[task.Name()] = append(matches[task.Name()], task)
+		}
+	case ImportModeReplace:
+		for _, task := range existings {
+			task := task
+			if err := cmd.TaskRepository.Delete(transaction, &task, now); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err := cmd.TaskRepository.Purge(transaction, task.ID(), now); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			result.Deleted++
+		}
+		for _, t := range trashed {
+			if err := cmd.TaskRepository.Purge(transaction, t.Task.ID(), now); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			result.Deleted++
+		}
+	default:
+		return nil, errors.Errorf("invalid import mode: %s", mode)
+	}
+
+	for i := range doc.Tasks {
+		taskDoc := &doc.Tasks[i]
+		task := &model.Task{TaskData: taskDoc}
+
+		if existing := matches.consume(taskDoc); existing != nil {
+			taskDoc.importID = existing.ID()
+			taskDoc.importVersion = existing.Version()
+			if err := cmd.TaskRepository.Update(transaction, task, now); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			result.Updated++
+		} else {
+			if err := cmd.TaskRepository.Create(transaction, task, now); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			result.Created++
+		}
+
+		dones := make([]model.DoneTask, len(taskDoc.History))
+		for j := range taskDoc.History {
+			dones[j] = model.DoneTask{DoneTaskData: &taskDoc.History[j]}
+		}
+		count, err := cmd.TaskRepository.ImportHistory(transaction, task, dones)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result.Dones += count
+	}
+
+	return result, nil
+}
+
+// taskMatches : the existing tasks by name, each task matches only one task document
+type taskMatches map[string][]model.Task
+
+// consume : the task having the exported id if exists, or the first one having the same name
+// NOTE: the names are not unique.
+func (matches taskMatches) consume(doc *TaskDocument) *model.Task {
+	tasks := matches[doc.TaskName]
+	if len(tasks) == 0 {
+		return nil
+	}
+	index := 0
+	for i, task := range tasks {
+		if task.ID() == doc.TaskID {
+			index = i
+			break
+		}
+	}
+	task := tasks[index]
+	matches[doc.TaskName] = append(tasks[:index:index], tasks[index+1:]...)
+	return &task
+}
+
+// PurgeTrash : the tasks trashed before the retention
+func (cmd *Command) PurgeTrash(retention time.Duration) (int, error) {
+	now := cmd.Clock.Now()
+	at := now.Add(-retention)
+	var count int
+	if err := cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		c, err := cmd.TaskRepository.PurgeBefore(transaction, at, now)
+		count = c
+		return err
+	}); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return count, nil
+}
+
+// Sync : merge the database file in both directions
+func (cmd *Command) Sync(path string) (*model.SyncResult, error) {
+	result, err := cmd.SyncRepository.Sync(path, cmd.Clock.Now())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result, nil
+}
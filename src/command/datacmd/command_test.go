@@ -0,0 +1,176 @@
+package datacmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/datastore/memoryimpl"
+	"github.com/notomo/counteria.nvim/src/datastore/sqliteimpl"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (clock testClock) Now() time.Time {
+	return clock.now
+}
+
+func setupCommand(t *testing.T) (*Command, *domain.Dep, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "counteria-datacmd")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	dep, err := sqliteimpl.Setup(
+		sqliteimpl.WithDataPath(filepath.Join(dir, "test.db")),
+		sqliteimpl.WithBackupPath(filepath.Join(dir, "backups")),
+	)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("%+v", err)
+	}
+	cmd := &Command{
+		Clock:              testClock{now: time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)},
+		TaskRepository:     dep.TaskRepository,
+		TransactionFactory: dep.TransactionFactory,
+		BackupRepository:   dep.BackupRepository,
+		SyncRepository:     dep.SyncRepository,
+	}
+	return cmd, dep, func() { os.RemoveAll(dir) }
+}
+
+func TestCommandImport(t *testing.T) {
+	cmd, dep, teardown := setupCommand(t)
+	defer teardown()
+
+	now := cmd.Clock.Now()
+	doneAt := now.AddDate(0, 0, -3)
+	active := dep.TaskRepository.Temporary(now)
+	trashed := dep.TaskRepository.Temporary(now)
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		if err := dep.TaskRepository.Create(transaction, active, now); err != nil {
+			return err
+		}
+		if err := dep.TaskRepository.Done(transaction, active, doneAt, nil); err != nil {
+			return err
+		}
+		if err := dep.TaskRepository.Create(transaction, trashed, now); err != nil {
+			return err
+		}
+		return dep.TaskRepository.Delete(transaction, trashed, now)
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	var exported bytes.Buffer
+	if err := cmd.Export(&exported); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(exported.Bytes(), &doc); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(doc.Tasks) != 1 {
+		t.Fatalf("should export the active task, but: %v", doc.Tasks)
+	}
+	doc.Tasks[0].History = append(doc.Tasks[0].History,
+		DoneDocument{TaskName: "old", DoneAt: now.AddDate(0, 0, -2)},
+		DoneDocument{TaskName: "old", DoneAt: now.AddDate(0, 0, -1)},
+	)
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		result, err := cmd.Import(bytes.NewReader(b), ImportModeMerge)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if result.Updated != 1 || result.Dones != 2 {
+			t.Errorf("should update the task and add the new dones, but: %s", result)
+		}
+
+		task, err := dep.TaskRepository.One(active.ID())
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		// NOTE: created, done and updated by the import
+		if task.Version() != 3 {
+			t.Errorf("should not change the version by the dones, but: %d", task.Version())
+		}
+		events, err := dep.EventRepository.Count(repository.EventListOption{TaskID: active.ID()})
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if events != 3 {
+			t.Errorf("should not append the events by the dones, but: %d", events)
+		}
+		history, err := dep.TaskRepository.History(active.ID())
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if len(history) != 3 {
+			t.Errorf("should keep the history unique, but: %d", len(history))
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		result, err := cmd.Import(bytes.NewReader(b), ImportModeReplace)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if result.Deleted != 2 || result.Created != 1 || result.Dones != 3 {
+			t.Errorf("should replace the active and the trashed tasks, but: %s", result)
+		}
+
+		trash, err := dep.TaskRepository.Trash()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if len(trash) != 0 {
+			t.Errorf("should purge the trash, but: %v", trash)
+		}
+		if _, err := dep.TaskRepository.One(trashed.ID()); err != domain.ErrNotFound {
+			t.Errorf("should purge the trashed task, but: %v", err)
+		}
+	})
+}
+
+func TestCommandImportReplaceWithoutBackup(t *testing.T) {
+	dep := memoryimpl.Setup()
+	cmd := &Command{
+		Clock:              testClock{now: time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)},
+		TaskRepository:     dep.TaskRepository,
+		TransactionFactory: dep.TransactionFactory,
+		BackupRepository:   dep.BackupRepository,
+		SyncRepository:     dep.SyncRepository,
+	}
+
+	now := cmd.Clock.Now()
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return dep.TaskRepository.Create(transaction, dep.TaskRepository.Temporary(now), now)
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	b, err := json.Marshal(Document{Version: DocumentVersion})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	result, err := cmd.Import(bytes.NewReader(b), ImportModeReplace)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if result.Deleted != 1 {
+		t.Errorf("should replace without the backup, but: %s", result)
+	}
+}
@@ -0,0 +1,247 @@
+package datacmd
+
+import (
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+// DocumentVersion : increment on incompatible changes
+const DocumentVersion = 1
+
+// Document : all tasks with the rule lines and the done history
+type Document struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Tasks      []TaskDocument `json:"tasks"`
+}
+
+var _ model.TaskData = &TaskDocument{}
+
+// TaskDocument :
+type TaskDocument struct {
+	// only for reference, not imported
+	TaskID      int            `json:"id"`
+	TaskName    string         `json:"name"`
+	TaskNotes   string         `json:"notes"`
+	TaskStartAt time.Time      `json:"startAt"`
+	TaskRule    RuleDocument   `json:"rule"`
+	History     []DoneDocument `json:"history"`
+
+	// the id and the version to import
+	importID      int
+	importVersion int
+}
+
+func newTaskDocument(task model.Task, history []model.DoneTask) TaskDocument {
+	dones := []DoneDocument{}
+	for _, d := range history {
+		dones = append(dones, DoneDocument{
+			TaskName:         d.Name(),
+			DoneAt:           d.At(),
+			DoneOccurrenceAt: d.OccurrenceAt(),
+		})
+	}
+	return TaskDocument{
+		TaskID:      task.ID(),
+		TaskName:    task.Name(),
+		TaskNotes:   task.Notes(),
+		TaskStartAt: task.StartAt(),
+		TaskRule:    newRuleDocument(task.Rule()),
+		History:     dones,
+	}
+}
+
+// ID :
+func (doc *TaskDocument) ID() int {
+	return doc.importID
+}
+
+// Version :
+func (doc *TaskDocument) Version() int {
+	return doc.importVersion
+}
+
+// Name :
+func (doc *TaskDocument) Name() string {
+	return doc.TaskName
+}
+
+// Notes :
+func (doc *TaskDocument) Notes() string {
+	return doc.TaskNotes
+}
+
+// Rule :
+func (doc *TaskDocument) Rule() *model.TaskRule {
+	return &model.TaskRule{TaskRuleData: &doc.TaskRule}
+}
+
+// StartAt :
+func (doc *TaskDocument) StartAt() time.Time {
+	return doc.TaskStartAt
+}
+
+// LastDone :
+func (doc *TaskDocument) LastDone() *model.DoneTask {
+	return nil
+}
+
+// DoneDocument :
+type DoneDocument struct {
+	TaskName         string     `json:"name"`
+	DoneAt           time.Time  `json:"at"`
+	DoneOccurrenceAt *time.Time `json:"occurrenceAt,omitempty"`
+}
+
+var _ model.DoneTaskData = &DoneDocument{}
+
+// Name : the task name at done
+func (doc *DoneDocument) Name() string {
+	return doc.TaskName
+}
+
+// At :
+func (doc *DoneDocument) At() time.Time {
+	return doc.DoneAt
+}
+
+// OccurrenceAt :
+func (doc *DoneDocument) OccurrenceAt() *time.Time {
+	return doc.DoneOccurrenceAt
+}
+
+var _ model.TaskRuleData = &RuleDocument{}
+
+// RuleDocument :
+type RuleDocument struct {
+	RuleType model.TaskRuleType `json:"type"`
+	Lines    []RuleLineDocument `json:"lines"`
+}
+
+// RuleLineDocument : one of the fields is set
+type RuleLineDocument struct {
+	Weekday  *model.Weekday  `json:"weekday,omitempty"`
+	Day      *model.Day      `json:"day,omitempty"`
+	MonthDay *model.MonthDay `json:"monthDay,omitempty"`
+	DateTime *time.Time      `json:"dateTime,omitempty"`
+	Date     *model.Date     `json:"date,omitempty"`
+	Period   *PeriodDocument `json:"period,omitempty"`
+}
+
+func newRuleDocument(rule *model.TaskRule) RuleDocument {
+	lines := []RuleLineDocument{}
+	for _, v := range rule.Weekdays() {
+		v := v
+		lines = append(lines, RuleLineDocument{Weekday: &v})
+	}
+	for _, v := range rule.Days() {
+		v := v
+		lines = append(lines, RuleLineDocument{Day: &v})
+	}
+	for _, v := range rule.MonthDays() {
+		v := v
+		lines = append(lines, RuleLineDocument{MonthDay: &v})
+	}
+	for _, v := range rule.DateTimes() {
+		v := v
+		lines = append(lines, RuleLineDocument{DateTime: &v})
+	}
+	for _, v := range rule.Dates() {
+		v := v
+		lines = append(lines, RuleLineDocument{Date: &v})
+	}
+	for _, v := range rule.Periods() {
+		lines = append(lines, RuleLineDocument{Period: &PeriodDocument{PeriodNumber: v.Number(), PeriodUnit: v.Unit()}})
+	}
+	return RuleDocument{RuleType: rule.Type(), Lines: lines}
+}
+
+// Type :
+func (doc *RuleDocument) Type() model.TaskRuleType {
+	return doc.RuleType
+}
+
+// Weekdays :
+func (doc *RuleDocument) Weekdays() model.Weekdays {
+	values := model.Weekdays{}
+	for _, line := range doc.Lines {
+		if line.Weekday != nil {
+			values = append(values, *line.Weekday)
+		}
+	}
+	return values
+}
+
+// Days :
+func (doc *RuleDocument) Days() model.Days {
+	values := model.Days{}
+	for _, line := range doc.Lines {
+		if line.Day != nil {
+			values = append(values, *line.Day)
+		}
+	}
+	return values
+}
+
+// MonthDays :
+func (doc *RuleDocument) MonthDays() model.MonthDays {
+	values := model.MonthDays{}
+	for _, line := range doc.Lines {
+		if line.MonthDay != nil {
+			values = append(values, *line.MonthDay)
+		}
+	}
+	return values
+}
+
+// DateTimes :
+func (doc *RuleDocument) DateTimes() model.DateTimes {
+	values := model.DateTimes{}
+	for _, line := range doc.Lines {
+		if line.DateTime != nil {
+			values = append(values, *line.DateTime)
+		}
+	}
+	return values
+}
+
+// Dates :
+func (doc *RuleDocument) Dates() model.Dates {
+	values := model.Dates{}
+	for _, line := range doc.Lines {
+		if line.Date != nil {
+			values = append(values, *line.Date)
+		}
+	}
+	return values
+}
+
+// Periods :
+func (doc *RuleDocument) Periods() model.Periods {
+	values := model.Periods{}
+	for _, line := range doc.Lines {
+		if line.Period != nil {
+			values = append(values, model.Period{PeriodData: *line.Period})
+		}
+	}
+	return values
+}
+
+var _ model.PeriodData = PeriodDocument{}
+
+// PeriodDocument :
+type PeriodDocument struct {
+	PeriodNumber int              `json:"number"`
+	PeriodUnit   model.PeriodUnit `json:"unit"`
+}
+
+// Number :
+func (doc PeriodDocument) Number() int {
+	return doc.PeriodNumber
+}
+
+// Unit :
+func (doc PeriodDocument) Unit() model.PeriodUnit {
+	return doc.PeriodUnit
+}
@@ -0,0 +1,168 @@
+package datacmd
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+const historyFilterDateFormat = "2006-01-02"
+
+// ParseHistoryFilter : from and to are yyyy-mm-dd, the to date is included. tasks is comma separated ids.
+func ParseHistoryFilter(from string, to string, tasks string) (repository.HistoryFilter, error) {
+	var filter repository.HistoryFilter
+	if from != "" {
+		t, err := time.ParseInLocation(historyFilterDateFormat, from, time.Local)
+		if err != nil {
+			return filter, errors.Errorf("invalid from: %s", from)
+		}
+		filter.From = &t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation(historyFilterDateFormat, to, time.Local)
+		if err != nil {
+			return filter, errors.Errorf("invalid to: %s", to)
+		}
+		t = t.AddDate(0, 0, 1)
+		filter.To = &t
+	}
+	for _, v := range strings.Split(tasks, ",") {
+		if v == "" {
+			continue
+		}
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, errors.Errorf("invalid task id: %s", v)
+		}
+		filter.TaskIDs = append(filter.TaskIDs, id)
+	}
+	return filter, nil
+}
+
+var historyHeader = []string{
+	"task_id",
+	"task_name",
+	// the task name at done
+	"done_name",
+	"done_at",
+	"deadline",
+	// done_at - deadline, negative if done before the deadline
+	"lateness_seconds",
+}
+
+type historyRow struct {
+	entry    model.HistoryEntry
+	deadline *time.Time
+}
+
+func (row historyRow) record() []string {
+	task := row.entry.Task
+	done := row.entry.Done
+	deadline := ""
+	lateness := ""
+	if row.deadline != nil {
+		deadline = row.deadline.Format(time.RFC3339)
+		lateness = strconv.Itoa(int(done.At().Sub(*row.deadline).Seconds()))
+	}
+	return []string{
+		strconv.Itoa(task.ID()),
+		task.Name(),
+		done.Name(),
+		done.At().Format(time.RFC3339),
+		deadline,
+		lateness,
+	}
+}
+
+// ExportHistory : the done history as csv ordered by done time
+func (cmd *Command) ExportHistory(w io.Writer, filter repository.HistoryFilter) error {
+	entries, err := cmd.TaskRepository.ListHistory(filter)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(historyHeader); err != nil {
+		return errors.WithStack(err)
+	}
+	for i, deadline := range historyDeadlines(entries) {
+		row := historyRow{entry: entries[i], deadline: deadline}
+		if err := writer.Write(row.record()); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// historyDeadlines : the due time which each done fulfills
+// NOTE: entries must be ordered by done time.
+func historyDeadlines(entries model.HistoryEntries) []*time.Time {
+	indexes := make(map[int][]int)
+	taskIDs := []int{}
+	for i, entry := range entries {
+		id := entry.Task.ID()
+		if _, ok := indexes[id]; !ok {
+			taskIDs = append(taskIDs, id)
+		}
+		indexes[id] = append(indexes[id], i)
+	}
+
+	deadlines := make([]*time.Time, len(entries))
+	for _, id := range taskIDs {
+		taskEntries := make(model.HistoryEntries, len(indexes[id]))
+		for i, index := range indexes[id] {
+			taskEntries[i] = entries[index]
+		}
+		for i, deadline := range taskDeadlines(taskEntries) {
+			deadlines[indexes[id][i]] = deadline
+		}
+	}
+	return deadlines
+}
+
+// taskDeadlines : the deadlines of the entries of one task
+// NOTE: periodic rule is restarted from the previous done.
+func taskDeadlines(entries model.HistoryEntries) []*time.Time {
+	deadlines := make([]*time.Time, len(entries))
+	task := entries[0].Task
+	rule := task.Rule()
+	typ := rule.Type()
+	switch typ {
+	case model.TaskRuleTypePeriodic:
+		for i, entry := range entries {
+			if at := entry.Done.OccurrenceAt(); at != nil {
+				deadlines[i] = at
+				continue
+			}
+			base := task.StartAt()
+			if entry.Previous != nil {
+				base = entry.Previous.Fulfilled()
+			}
+			deadlines[i] = rule.Periods().NextTime(base)
+		}
+		return deadlines
+	case model.TaskRuleTypeByTimes, model.TaskRuleTypeInDates, model.TaskRuleTypeInDaysEveryMonth, model.TaskRuleTypeInWeekdays:
+		dones := make([]model.DoneTask, len(entries))
+		for i, entry := range entries {
+			dones[i] = entry.Done
+		}
+		for i, at := range rule.FulfilledTimes(task.StartAt(), dones) {
+			at := at
+			deadlines[i] = &at
+		}
+		return deadlines
+	case model.TaskRuleTypeNone:
+		return deadlines
+	}
+	panic("unreachable: invalid rule type: " + typ)
+}
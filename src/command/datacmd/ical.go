@@ -0,0 +1,96 @@
+package datacmd
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// icalWriter : writes iCalendar (RFC 5545) content lines
+type icalWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newICalWriter(w io.Writer) *icalWriter {
+	return &icalWriter{w: bufio.NewWriter(w)}
+}
+
+const (
+	icalDateFormat     = "20060102"
+	icalDateTimeFormat = "20060102T150405Z"
+	// octets per line excluding CRLF
+	icalLineLimit = 75
+)
+
+// line : folds the line longer than the limit
+func (writer *icalWriter) line(line string) {
+	if writer.err != nil {
+		return
+	}
+
+	limit := icalLineLimit
+	for len(line) > limit {
+		i := limit
+		// NOTE: not to split a multibyte character
+		for i > 0 && line[i]&0xC0 == 0x80 {
+			i--
+		}
+		writer.write(line[:i] + "\r\n")
+		line = line[i:]
+		// the leading space of the continuation line
+		limit = icalLineLimit - 1
+		writer.write(" ")
+	}
+	writer.write(line + "\r\n")
+}
+
+func (writer *icalWriter) write(s string) {
+	if writer.err != nil {
+		return
+	}
+	_, writer.err = writer.w.WriteString(s)
+}
+
+func (writer *icalWriter) begin(component string) {
+	writer.line("BEGIN:" + component)
+}
+
+func (writer *icalWriter) end(component string) {
+	writer.line("END:" + component)
+}
+
+// text : escaped TEXT value
+func (writer *icalWriter) text(name string, value string) {
+	writer.line(name + ":" + icalTextReplacer.Replace(value))
+}
+
+// raw : the value is written as is
+func (writer *icalWriter) raw(name string, value string) {
+	writer.line(name + ":" + value)
+}
+
+func (writer *icalWriter) dateTime(name string, at time.Time) {
+	writer.line(name + ":" + at.UTC().Format(icalDateTimeFormat))
+}
+
+// date : the local date of the time
+func (writer *icalWriter) date(name string, at time.Time) {
+	writer.line(name + ";VALUE=DATE:" + at.Local().Format(icalDateFormat))
+}
+
+func (writer *icalWriter) flush() error {
+	if writer.err != nil {
+		return writer.err
+	}
+	return writer.w.Flush()
+}
+
+var icalTextReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
@@ -0,0 +1,76 @@
+package logcmd
+
+import (
+	"fmt"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/view"
+	"github.com/pkg/errors"
+)
+
+// Command :
+type Command struct {
+	Renderer *view.BufferRenderer
+
+	EventRepository repository.EventRepository
+}
+
+const pageSize = 100
+
+// PageCount : the number of the log pages, at least one
+func (cmd *Command) PageCount() (int, error) {
+	count, err := cmd.EventRepository.Count(repository.EventListOption{})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	pageCount := (count + pageSize - 1) / pageSize
+	if pageCount == 0 {
+		pageCount = 1
+	}
+	return pageCount, nil
+}
+
+// List : all tasks' events, newest first
+func (cmd *Command) List(page int) error {
+	pageCount, err := cmd.PageCount()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if page < 1 || pageCount < page {
+		return domain.ErrNotFound
+	}
+
+	option := repository.EventListOption{
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+
+	events, err := cmd.EventRepository.List(option)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	header := fmt.Sprintf("log page %d of %d", page, pageCount)
+	return cmd.Renderer.EventList(header, events)
+}
+
+// TaskList : the events of the task including the deleted one
+func (cmd *Command) TaskList(taskID int) error {
+	events, err := cmd.EventRepository.List(repository.EventListOption{TaskID: taskID})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	header := fmt.Sprintf("log of task %d", taskID)
+	return cmd.Renderer.EventList(header, events)
+}
+
+// One : the snapshots before and after the change
+func (cmd *Command) One(eventID int) error {
+	event, err := cmd.EventRepository.One(eventID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return cmd.Renderer.OneEvent(event)
+}
@@ -1,13 +1,20 @@
 package command
 
 import (
+	"regexp"
+	"sync"
+
 	"github.com/neovim/go-client/nvim"
+	"github.com/notomo/counteria.nvim/src/command/backupcmd"
+	"github.com/notomo/counteria.nvim/src/command/datacmd"
+	"github.com/notomo/counteria.nvim/src/command/logcmd"
 	"github.com/notomo/counteria.nvim/src/command/taskcmd"
 	"github.com/notomo/counteria.nvim/src/domain"
 	"github.com/notomo/counteria.nvim/src/lib"
 	"github.com/notomo/counteria.nvim/src/router/route"
 	"github.com/notomo/counteria.nvim/src/view"
 	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
 )
 
 // RootCommand :
@@ -16,18 +23,99 @@ type RootCommand struct {
 	BufferClientFactory *vimlib.BufferClientFactory
 	Redirector          *route.Redirector
 	Clock               lib.Clock
+	// the active profile name
+	Profile string
+	// OpenProfile : the dependencies of the profile, called on every switch
+	OpenProfile func(name string) (*domain.Dep, error)
+
+	mu sync.RWMutex
 	*domain.Dep
 }
 
+// DefaultProfile : the profile using the data path option
+const DefaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ErrInvalidProfile :
+var ErrInvalidProfile = errors.New("invalid profile name")
+
+// SwitchProfile : swap the dependencies without restarting
+func (root *RootCommand) SwitchProfile(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return errors.Wrap(ErrInvalidProfile, name)
+	}
+
+	dep, err := root.OpenProfile(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.Profile = name
+	root.Dep = dep
+	return nil
+}
+
+// ActiveProfile :
+func (root *RootCommand) ActiveProfile() string {
+	_, profile := root.current()
+	return profile
+}
+
+// current : the dependencies and the name of the active profile
+func (root *RootCommand) current() (*domain.Dep, string) {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	return root.Dep, root.Profile
+}
+
 // TaskCmd :
 func (root *RootCommand) TaskCmd(bufnr nvim.Buffer) *taskcmd.Command {
 	client := root.BufferClientFactory.Get(bufnr)
+	dep, profile := root.current()
 	return &taskcmd.Command{
 		Renderer:           root.Renderer.Buffer(client),
 		Buffer:             client,
 		Redirector:         root.Redirector,
 		Clock:              root.Clock,
-		TaskRepository:     root.TaskRepository,
-		TransactionFactory: root.TransactionFactory,
+		Profile:            profile,
+		TaskRepository:     dep.TaskRepository,
+		TransactionFactory: dep.TransactionFactory,
+		BackupRepository:   dep.BackupRepository,
+	}
+}
+
+// BackupCmd :
+func (root *RootCommand) BackupCmd(bufnr nvim.Buffer) *backupcmd.Command {
+	client := root.BufferClientFactory.Get(bufnr)
+	dep, _ := root.current()
+	return &backupcmd.Command{
+		Renderer:         root.Renderer.Buffer(client),
+		Redirector:       root.Redirector,
+		BackupRepository: dep.BackupRepository,
+	}
+}
+
+// LogCmd :
+func (root *RootCommand) LogCmd(bufnr nvim.Buffer) *logcmd.Command {
+	client := root.BufferClientFactory.Get(bufnr)
+	dep, _ := root.current()
+	return &logcmd.Command{
+		Renderer:        root.Renderer.Buffer(client),
+		EventRepository: dep.EventRepository,
+	}
+}
+
+// DataCmd :
+func (root *RootCommand) DataCmd() *datacmd.Command {
+	dep, _ := root.current()
+	return &datacmd.Command{
+		Clock:              root.Clock,
+		TaskRepository:     dep.TaskRepository,
+		TransactionFactory: dep.TransactionFactory,
+		BackupRepository:   dep.BackupRepository,
+		SyncRepository:     dep.SyncRepository,
 	}
 }
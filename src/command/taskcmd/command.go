@@ -1,6 +1,11 @@
 package taskcmd
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
 	"github.com/notomo/counteria.nvim/src/domain/repository"
 	"github.com/notomo/counteria.nvim/src/lib"
 	"github.com/notomo/counteria.nvim/src/router/route"
@@ -15,20 +20,63 @@ type Command struct {
 	Buffer     *vimlib.BufferClient
 	Redirector *route.Redirector
 	Clock      lib.Clock
+	// the active profile name
+	Profile string
 
 	TaskRepository     repository.TaskRepository
 	TransactionFactory repository.TransactionFactory
+	BackupRepository   repository.BackupRepository
+}
+
+const pageSize = 100
+
+// PageCount : the number of the list pages, at least one
+func (cmd *Command) PageCount(query route.Query) (int, error) {
+	count, err := cmd.TaskRepository.Count(newListOption(query), cmd.Clock.Now())
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	pageCount := (count + pageSize - 1) / pageSize
+	if pageCount == 0 {
+		pageCount = 1
+	}
+	return pageCount, nil
 }
 
 // List :
-func (cmd *Command) List() error {
+func (cmd *Command) List(page int, query route.Query) error {
+	pageCount, err := cmd.PageCount(query)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if page < 1 || pageCount < page {
+		return domain.ErrNotFound
+	}
+
+	option := newListOption(query)
+	option.Limit = pageSize
+	option.Offset = (page - 1) * pageSize
+
+	now := cmd.Clock.Now()
+	tasks, err := cmd.TaskRepository.List(option, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	header := fmt.Sprintf("[%s] page %d of %d", cmd.Profile, page, pageCount)
+	if rawQuery := query.String(); rawQuery != "" {
+		header += "  " + rawQuery
+	}
+	return cmd.Renderer.TaskList(header, tasks, now)
+}
+
+// Agenda :
+func (cmd *Command) Agenda() error {
 	option := repository.ListOption{
 		Sort: repository.Sort{
 			By:    repository.SortByTaskRemains,
-			Order: repository.SortOrderDesc,
+			Order: repository.SortOrderAsc,
 		},
-		Limit:  100,
-		Offset: 0,
 	}
 
 	now := cmd.Clock.Now()
@@ -37,36 +85,36 @@ func (cmd *Command) List() error {
 		return errors.WithStack(err)
 	}
 
-	return cmd.Renderer.TaskList(tasks, now)
+	return cmd.Renderer.Agenda(tasks, now)
 }
 
-// Create :
-func (cmd *Command) Create() error {
-	var newTaskID int
-	task, err := cmd.Renderer.TaskFromForm(newTaskID)
+// Search : tasks matched by the words
+func (cmd *Command) Search(words string) error {
+	hits, err := cmd.TaskRepository.SearchTasks(words)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	transaction, err := cmd.TransactionFactory.Begin()
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	if err := cmd.TaskRepository.Create(transaction, task); err != nil {
-		if err := transaction.Rollback(); err != nil {
-			return errors.WithStack(err)
-		}
-		return errors.WithStack(err)
-	}
-	if err := transaction.Commit(); err != nil {
-		return errors.WithStack(err)
-	}
+	header := fmt.Sprintf("%d tasks matched: %s", len(hits), words)
+	return cmd.Renderer.SearchResult(header, hits)
+}
 
-	if err := cmd.Buffer.Save(); err != nil {
+// Create :
+func (cmd *Command) Create() error {
+	var newTaskID int
+	written, err := cmd.Renderer.TaskFromForm(newTaskID)
+	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	return cmd.Redirector.ToTasksOne(task.ID())
+	now := cmd.Clock.Now()
+	var task model.Task
+	return cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		task = *written
+		return cmd.TaskRepository.Create(transaction, &task, now)
+	}, cmd.Buffer.Save, func() error {
+		return cmd.Redirector.ToTasksOne(task.ID())
+	})
 }
 
 // ShowOne :
@@ -85,28 +133,17 @@ func (cmd *Command) CreateForm() error {
 	return cmd.Renderer.OneTask(task)
 }
 
-// Delete :
+// Delete : move to the trash
 func (cmd *Command) Delete(taskID int) error {
 	task, err := cmd.TaskRepository.One(taskID)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	transaction, err := cmd.TransactionFactory.Begin()
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	if err := cmd.TaskRepository.Delete(transaction, task); err != nil {
-		if err := transaction.Rollback(); err != nil {
-			return errors.WithStack(err)
-		}
-		return errors.WithStack(err)
-	}
-	if err := transaction.Commit(); err != nil {
-		return errors.WithStack(err)
-	}
-
-	return cmd.Redirector.ToTasksList()
+	now := cmd.Clock.Now()
+	return cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return cmd.TaskRepository.Delete(transaction, task, now)
+	}, cmd.redirectToList)
 }
 
 // Done :
@@ -117,7 +154,9 @@ func (cmd *Command) Done(taskID int) error {
 	}
 
 	now := cmd.Clock.Now()
-	if !task.IsActive(now) {
+	deadline := task.Deadline(now)
+	missed := deadline.Missed()
+	if len(missed) == 0 && !task.IsActive(now) {
 		return cmd.Renderer.Warn("not active")
 	}
 
@@ -125,43 +164,95 @@ func (cmd *Command) Done(taskID int) error {
 		return cmd.Renderer.Warn("already done")
 	}
 
-	transaction, err := cmd.TransactionFactory.Begin()
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	if err := cmd.TaskRepository.Done(transaction, task, now); err != nil {
-		if err := transaction.Rollback(); err != nil {
+	mode := model.DoneModeCatchUp
+	if len(missed) > 1 {
+		msg := fmt.Sprintf("missed %d times since %s", len(missed), missed[0].Format("2006-01-02 15:04"))
+		choice, err := cmd.Renderer.Choose(msg,
+			"&Catch up once and reschedule from now",
+			"Mark only the &oldest missed occurrence",
+		)
+		if err != nil {
 			return errors.WithStack(err)
 		}
-		return errors.WithStack(err)
-	}
-	if err := transaction.Commit(); err != nil {
-		return errors.WithStack(err)
+		if choice < 0 {
+			return nil
+		}
+		if choice == 1 {
+			mode = model.DoneModeOldest
+		}
 	}
 
-	return cmd.Redirector.ToTasksList()
+	occurrenceAt := deadline.Fulfill(mode)
+	return cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return cmd.TaskRepository.Done(transaction, task, now, occurrenceAt)
+	}, cmd.redirectToList)
 }
 
-// Update :
+// Update : shows the conflict if the task was changed after opened
 func (cmd *Command) Update(taskID int) error {
-	task, err := cmd.Renderer.TaskFromForm(taskID)
+	written, err := cmd.Renderer.TaskFromForm(taskID)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	transaction, err := cmd.TransactionFactory.Begin()
+	now := cmd.Clock.Now()
+	var task model.Task
+	err = cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		task = *written
+		return cmd.TaskRepository.Update(transaction, &task, now)
+	}, func() error {
+		return cmd.ShowOne(task.ID())
+	})
+	if errors.Cause(err) == domain.ErrConflict {
+		return cmd.conflict(written)
+	}
+	return err
+}
+
+// Calendar :
+func (cmd *Command) Calendar(year int, month time.Month) error {
+	if month < time.January || time.December < month {
+		return domain.ErrNotFound
+	}
+
+	from := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, 0)
+	occurrences, err := cmd.TaskRepository.Occurrences(from, to)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	if err := cmd.TaskRepository.Update(transaction, task); err != nil {
-		if err := transaction.Rollback(); err != nil {
-			return errors.WithStack(err)
-		}
+
+	return cmd.Renderer.Calendar(year, month, occurrences, cmd.Clock.Now())
+}
+
+// CalendarDay :
+func (cmd *Command) CalendarDay(year int, month time.Month, day int) error {
+	from := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	if from.Month() != month || from.Day() != day {
+		return domain.ErrNotFound
+	}
+
+	to := from.AddDate(0, 0, 1)
+	occurrences, err := cmd.TaskRepository.Occurrences(from, to)
+	if err != nil {
 		return errors.WithStack(err)
 	}
-	if err := transaction.Commit(); err != nil {
+
+	return cmd.Renderer.TaskList(from.Format("2006-01-02"), occurrences.Tasks(), cmd.Clock.Now())
+}
+
+func (cmd *Command) conflict(written *model.Task) error {
+	stored, err := cmd.TaskRepository.One(written.ID())
+	if err != nil {
 		return errors.WithStack(err)
 	}
+	return cmd.Renderer.Conflict(stored, written)
+}
 
-	return cmd.ShowOne(task.ID())
+func (cmd *Command) redirectToList() error {
+	path, err := cmd.Buffer.Path()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return cmd.Redirector.ToListOf(path)
 }
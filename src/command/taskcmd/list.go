@@ -0,0 +1,40 @@
+package taskcmd
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/router/route"
+)
+
+// newListOption : from the list page query, the default is `sort=remains&order=asc`
+func newListOption(query route.Query) repository.ListOption {
+	var by repository.SortBy
+	switch query.Sort {
+	case route.QuerySortName:
+		by = repository.SortByTaskName
+	case route.QuerySortDoneAt:
+		by = repository.SortByTaskDoneAt
+	default:
+		by = repository.SortByTaskRemains
+	}
+
+	order := repository.SortOrderAsc
+	if query.Order == route.QueryOrderDesc {
+		order = repository.SortOrderDesc
+	}
+
+	return repository.ListOption{
+		Sort: repository.Sort{
+			By:    by,
+			Order: order,
+		},
+		Filter: repository.Filter{
+			RuleTypes: query.Rules,
+			Name:      query.Name,
+			StartFrom: query.StartFrom,
+			StartTo:   query.StartTo,
+			Active:    query.HasFilter(route.QueryFilterActive),
+			Overdue:   query.HasFilter(route.QueryFilterOverdue),
+			Done:      query.HasFilter(route.QueryFilterDone),
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package taskcmd
+
+import (
+	"fmt"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// Trash : deleted tasks
+func (cmd *Command) Trash() error {
+	trashed, err := cmd.TaskRepository.Trash()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return cmd.Renderer.Trash(trashed)
+}
+
+// Restore : the trashed task
+func (cmd *Command) Restore(taskID int) error {
+	task, err := cmd.trashed(taskID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := cmd.Clock.Now()
+	return cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return cmd.TaskRepository.Restore(transaction, taskID, now)
+	}, func() error {
+		return cmd.Renderer.Info("restored: " + task.Name())
+	}, cmd.Redirector.ToTrash)
+}
+
+// Purge : after confirmation and backing up
+func (cmd *Command) Purge(taskID int) error {
+	task, err := cmd.trashed(taskID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	msg := fmt.Sprintf("purge %s? the done history is also deleted.", task.Name())
+	choice, err := cmd.Renderer.Choose(msg, "&Yes", "&No")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if choice != 0 {
+		return nil
+	}
+
+	if _, err := cmd.BackupRepository.Backup(model.BackupReasonPurge); err != nil && errors.Cause(err) != domain.ErrNotSupported {
+		return errors.WithStack(err)
+	}
+
+	now := cmd.Clock.Now()
+	return cmd.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return cmd.TaskRepository.Purge(transaction, taskID, now)
+	}, cmd.Redirector.ToTrash)
+}
+
+func (cmd *Command) trashed(taskID int) (*model.TrashedTask, error) {
+	trashed, err := cmd.TaskRepository.Trash()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, task := range trashed {
+		if task.ID() == taskID {
+			return &task, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
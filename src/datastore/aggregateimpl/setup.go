@@ -0,0 +1,24 @@
+package aggregateimpl
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain"
+)
+
+// Source : a named datastore to aggregate
+type Source struct {
+	Name string
+	*domain.Dep
+}
+
+// Setup : dependencies listing the tasks of all sources
+// The first source is the primary one. New tasks, backups, events and sync belong to it.
+func Setup(sources ...Source) *domain.Dep {
+	primary := sources[0]
+	return &domain.Dep{
+		TaskRepository:     &TaskRepository{Sources: sources},
+		TransactionFactory: &TransactionFactory{Sources: sources},
+		BackupRepository:   primary.BackupRepository,
+		EventRepository:    primary.EventRepository,
+		SyncRepository:     primary.SyncRepository,
+	}
+}
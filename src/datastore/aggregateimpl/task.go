@@ -0,0 +1,343 @@
+package aggregateimpl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// TaskRepository : impl, reads from all sources and writes to the owning one
+type TaskRepository struct {
+	Sources []Source
+}
+
+var _ repository.TaskRepository = &TaskRepository{}
+
+// NOTE: the ids of the primary source are not changed.
+const idStride = 1000000000
+
+// locate : the source index and the id in the source
+func (repo *TaskRepository) locate(id int) (int, int, error) {
+	index := id / idStride
+	if id <= 0 || len(repo.Sources) <= index {
+		return 0, 0, domain.ErrNotFound
+	}
+	return index, id % idStride, nil
+}
+
+// sourced : the task with the aggregated id and the source name
+func (repo *TaskRepository) sourced(index int, task model.Task) model.Task {
+	return model.Task{TaskData: sourcedTask{
+		TaskData: task.TaskData,
+		id:       index*idStride + task.ID(),
+		source:   repo.Sources[index].Name,
+	}}
+}
+
+// local : the source index and the task with the id in the source
+func (repo *TaskRepository) local(task *model.Task) (int, *model.Task, error) {
+	if task.ID() == 0 {
+		return 0, task, nil
+	}
+	index, id, err := repo.locate(task.ID())
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, &model.Task{TaskData: localTask{TaskData: task.TaskData, id: id}}, nil
+}
+
+func (repo *TaskRepository) transaction(transaction repository.Transaction, index int) (repository.Transaction, error) {
+	return transaction.(*Transaction).source(index)
+}
+
+// List : merged and sorted again
+func (repo *TaskRepository) List(option repository.ListOption, now time.Time) ([]model.Task, error) {
+	// NOTE: the first offset + limit tasks of each source are enough
+	sourceOption := option
+	sourceOption.Offset = 0
+	if option.Limit > 0 {
+		sourceOption.Limit = option.Offset + option.Limit
+	}
+
+	merged := []model.Task{}
+	for i, source := range repo.Sources {
+		tasks, err := source.TaskRepository.List(sourceOption, now)
+		if err != nil {
+			return nil, errors.Wrap(err, source.Name)
+		}
+		for _, task := range tasks {
+			merged = append(merged, repo.sourced(i, task))
+		}
+	}
+	option.Sort.Apply(merged, now)
+
+	start, end := option.Range(len(merged))
+	return merged[start:end], nil
+}
+
+// Count : ignoring limit and offset
+func (repo *TaskRepository) Count(option repository.ListOption, now time.Time) (int, error) {
+	sum := 0
+	for _, source := range repo.Sources {
+		count, err := source.TaskRepository.Count(option, now)
+		if err != nil {
+			return 0, errors.Wrap(err, source.Name)
+		}
+		sum += count
+	}
+	return sum, nil
+}
+
+// Create : in the primary source
+func (repo *TaskRepository) Create(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans, err := repo.transaction(transaction, 0)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := repo.Sources[0].TaskRepository.Create(trans, task, now); err != nil {
+		return errors.WithStack(err)
+	}
+	*task = repo.sourced(0, *task)
+	return nil
+}
+
+// Update :
+func (repo *TaskRepository) Update(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	index, local, err := repo.local(task)
+	if err != nil {
+		return err
+	}
+	trans, err := repo.transaction(transaction, index)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := repo.Sources[index].TaskRepository.Update(trans, local, now); err != nil {
+		return errors.WithStack(err)
+	}
+	*task = repo.sourced(index, *local)
+	return nil
+}
+
+// Delete :
+func (repo *TaskRepository) Delete(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	index, local, err := repo.local(task)
+	if err != nil {
+		return err
+	}
+	trans, err := repo.transaction(transaction, index)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return repo.Sources[index].TaskRepository.Delete(trans, local, now)
+}
+
+// Done :
+func (repo *TaskRepository) Done(transaction repository.Transaction, task *model.Task, now time.Time, occurrenceAt *time.Time) error {
+	index, local, err := repo.local(task)
+	if err != nil {
+		return err
+	}
+	trans, err := repo.transaction(transaction, index)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return repo.Sources[index].TaskRepository.Done(trans, local, now, occurrenceAt)
+}
+
+// ImportHistory :
+func (repo *TaskRepository) ImportHistory(transaction repository.Transaction, task *model.Task, dones []model.DoneTask) (int, error) {
+	index, local, err := repo.local(task)
+	if err != nil {
+		return 0, err
+	}
+	trans, err := repo.transaction(transaction, index)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return repo.Sources[index].TaskRepository.ImportHistory(trans, local, dones)
+}
+
+// One :
+func (repo *TaskRepository) One(id int) (*model.Task, error) {
+	index, localID, err := repo.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := repo.Sources[index].TaskRepository.One(localID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sourced := repo.sourced(index, *task)
+	return &sourced, nil
+}
+
+// Temporary : of the primary source
+func (repo *TaskRepository) Temporary(now time.Time) *model.Task {
+	return repo.Sources[0].TaskRepository.Temporary(now)
+}
+
+// Occurrences : merged and sorted again
+func (repo *TaskRepository) Occurrences(from time.Time, to time.Time) (model.Occurrences, error) {
+	merged := model.Occurrences{}
+	for i, source := range repo.Sources {
+		occurrences, err := source.TaskRepository.Occurrences(from, to)
+		if err != nil {
+			return nil, errors.Wrap(err, source.Name)
+		}
+		for _, o := range occurrences {
+			o.Task = repo.sourced(i, o.Task)
+			merged = append(merged, o)
+		}
+	}
+	merged.Sort()
+	return merged, nil
+}
+
+// SearchTasks : ordered by relevance in each source, the primary first
+func (repo *TaskRepository) SearchTasks(words string) ([]model.SearchHit, error) {
+	merged := []model.SearchHit{}
+	for i, source := range repo.Sources {
+		hits, err := source.TaskRepository.SearchTasks(words)
+		if err != nil {
+			return nil, errors.Wrap(err, source.Name)
+		}
+		for _, hit := range hits {
+			hit.Task = repo.sourced(i, hit.Task)
+			merged = append(merged, hit)
+		}
+	}
+	return merged, nil
+}
+
+// History :
+func (repo *TaskRepository) History(taskID int) ([]model.DoneTask, error) {
+	index, localID, err := repo.locate(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Sources[index].TaskRepository.History(localID)
+}
+
+// ListHistory : merged and ordered by done at
+func (repo *TaskRepository) ListHistory(filter repository.HistoryFilter) (model.HistoryEntries, error) {
+	merged := model.HistoryEntries{}
+	for i, source := range repo.Sources {
+		sourceFilter := filter
+		if len(filter.TaskIDs) != 0 {
+			sourceFilter.TaskIDs = []int{}
+			for _, id := range filter.TaskIDs {
+				index, localID, err := repo.locate(id)
+				if err != nil || index != i {
+					continue
+				}
+				sourceFilter.TaskIDs = append(sourceFilter.TaskIDs, localID)
+			}
+			if len(sourceFilter.TaskIDs) == 0 {
+				continue
+			}
+		}
+
+		entries, err := source.TaskRepository.ListHistory(sourceFilter)
+		if err != nil {
+			return nil, errors.Wrap(err, source.Name)
+		}
+		for _, entry := range entries {
+			entry.Task = repo.sourced(i, entry.Task)
+			merged = append(merged, entry)
+		}
+	}
+	merged.Sort()
+	return merged, nil
+}
+
+// Trash : merged and ordered by deleted at desc
+func (repo *TaskRepository) Trash() ([]model.TrashedTask, error) {
+	merged := []model.TrashedTask{}
+	for i, source := range repo.Sources {
+		trashed, err := source.TaskRepository.Trash()
+		if err != nil {
+			return nil, errors.Wrap(err, source.Name)
+		}
+		for _, task := range trashed {
+			task.Task = repo.sourced(i, task.Task)
+			merged = append(merged, task)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].DeletedAt.After(merged[j].DeletedAt)
+	})
+	return merged, nil
+}
+
+// Restore :
+func (repo *TaskRepository) Restore(transaction repository.Transaction, taskID int, now time.Time) error {
+	index, localID, err := repo.locate(taskID)
+	if err != nil {
+		return err
+	}
+	trans, err := repo.transaction(transaction, index)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return repo.Sources[index].TaskRepository.Restore(trans, localID, now)
+}
+
+// Purge :
+func (repo *TaskRepository) Purge(transaction repository.Transaction, taskID int, now time.Time) error {
+	index, localID, err := repo.locate(taskID)
+	if err != nil {
+		return err
+	}
+	trans, err := repo.transaction(transaction, index)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return repo.Sources[index].TaskRepository.Purge(trans, localID, now)
+}
+
+// PurgeBefore : in the primary source only
+// NOTE: the attached sources are shared, so they are purged with their own trash days and backups.
+func (repo *TaskRepository) PurgeBefore(transaction repository.Transaction, at time.Time, now time.Time) (int, error) {
+	primary := repo.Sources[0]
+	trans, err := repo.transaction(transaction, 0)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	count, err := primary.TaskRepository.PurgeBefore(trans, at, now)
+	if err != nil {
+		return 0, errors.Wrap(err, primary.Name)
+	}
+	return count, nil
+}
+
+// sourcedTask : the task with the aggregated id
+type sourcedTask struct {
+	model.TaskData
+	id     int
+	source string
+}
+
+// ID :
+func (task sourcedTask) ID() int {
+	return task.id
+}
+
+// Source :
+func (task sourcedTask) Source() string {
+	return task.source
+}
+
+// localTask : the task with the id in the source
+type localTask struct {
+	model.TaskData
+	id int
+}
+
+// ID :
+func (task localTask) ID() int {
+	return task.id
+}
@@ -0,0 +1,58 @@
+package aggregateimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/datastore/memoryimpl"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+func TestTaskRepositoryPurgeBefore(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	primary := memoryimpl.Setup()
+	attached := memoryimpl.Setup()
+	for _, dep := range []*domain.Dep{primary, attached} {
+		dep := dep
+		if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+			task := dep.TaskRepository.Temporary(now)
+			if err := dep.TaskRepository.Create(transaction, task, now); err != nil {
+				return err
+			}
+			return dep.TaskRepository.Delete(transaction, task, now)
+		}); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	dep := Setup(Source{Name: "primary", Dep: primary}, Source{Name: "attached", Dep: attached})
+	var count int
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		c, err := dep.TaskRepository.PurgeBefore(transaction, now.AddDate(0, 0, 1), now)
+		count = c
+		return err
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if count != 1 {
+		t.Errorf("should purge only in the primary source, but: %d", count)
+	}
+
+	for _, c := range []struct {
+		name string
+		dep  *domain.Dep
+		want int
+	}{
+		{name: "primary", dep: primary, want: 0},
+		{name: "attached", dep: attached, want: 1},
+	} {
+		trash, err := c.dep.TaskRepository.Trash()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if len(trash) != c.want {
+			t.Errorf("%s trash should have %d tasks, but: %d", c.name, c.want, len(trash))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package aggregateimpl
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+var _ repository.TransactionFactory = &TransactionFactory{}
+
+// TransactionFactory : impl
+type TransactionFactory struct {
+	Sources []Source
+}
+
+// the waits between retries while a source is busy
+var retryWaits = []time.Duration{
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+}
+
+// Begin : the transactions of the sources begin on the first use
+func (factory *TransactionFactory) Begin() (repository.Transaction, error) {
+	return &Transaction{
+		sources:      factory.Sources,
+		transactions: make(map[int]repository.Transaction),
+	}, nil
+}
+
+// Do : retry the whole work while a source is busy
+func (factory *TransactionFactory) Do(work func(repository.Transaction) error, afterCommits ...func() error) error {
+	return repository.RunUnitOfWork(factory.Begin, retryWaits, isBusy, work, afterCommits...)
+}
+
+func isBusy(err error) bool {
+	return errors.Cause(err) == domain.ErrBusy
+}
+
+var _ repository.Transaction = &Transaction{}
+
+// Transaction : holds a transaction per used source
+// NOTE: not atomic across the sources, a failed commit after another source's commit is not rolled back.
+type Transaction struct {
+	sources      []Source
+	transactions map[int]repository.Transaction
+	finished     bool
+}
+
+// source : the transaction of the source
+func (trans *Transaction) source(index int) (repository.Transaction, error) {
+	if transaction, ok := trans.transactions[index]; ok {
+		return transaction, nil
+	}
+	transaction, err := trans.sources[index].TransactionFactory.Begin()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	trans.transactions[index] = transaction
+	return transaction, nil
+}
+
+// Commit : in the source order
+func (trans *Transaction) Commit() error {
+	if trans.finished {
+		return errors.WithStack(sql.ErrTxDone)
+	}
+	trans.finished = true
+
+	committed := []string{}
+	for i, source := range trans.sources {
+		transaction, ok := trans.transactions[i]
+		if !ok {
+			continue
+		}
+		if err := transaction.Commit(); err != nil {
+			// NOTE: the commit error is more important than the rollback error
+			_ = trans.rollback(i + 1)
+			if len(committed) == 0 {
+				return errors.WithStack(err)
+			}
+			// NOTE: not busy to prevent retrying the committed changes
+			return errors.Errorf("committed to %v but failed on %s: %v", committed, source.Name, err)
+		}
+		committed = append(committed, source.Name)
+	}
+	return nil
+}
+
+// Rollback : all used sources
+func (trans *Transaction) Rollback() error {
+	if trans.finished {
+		return errors.WithStack(sql.ErrTxDone)
+	}
+	trans.finished = true
+	return trans.rollback(0)
+}
+
+// rollback : the transactions from the source index
+func (trans *Transaction) rollback(from int) error {
+	var rollbackErr error
+	for i := from; i < len(trans.sources); i++ {
+		transaction, ok := trans.transactions[i]
+		if !ok {
+			continue
+		}
+		if err := transaction.Rollback(); err != nil && rollbackErr == nil {
+			rollbackErr = errors.WithStack(err)
+		}
+	}
+	return rollbackErr
+}
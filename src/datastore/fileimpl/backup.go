@@ -0,0 +1,29 @@
+package fileimpl
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// BackupRepository : impl, not supported
+// NOTE: the plain text files can be versioned by other tools.
+type BackupRepository struct{}
+
+var _ repository.BackupRepository = &BackupRepository{}
+
+// Backup : always not supported
+func (repo *BackupRepository) Backup(reason model.BackupReason) (*model.Backup, error) {
+	return nil, errors.Wrap(domain.ErrNotSupported, "backup by the file datastore")
+}
+
+// List : always empty
+func (repo *BackupRepository) List() ([]model.Backup, error) {
+	return []model.Backup{}, nil
+}
+
+// Restore : always not found
+func (repo *BackupRepository) Restore(id int) error {
+	return domain.ErrNotFound
+}
@@ -0,0 +1,28 @@
+package fileimpl
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+// EventRepository : impl, not supported
+// NOTE: the changes of the plain text files can be tracked by other tools.
+type EventRepository struct{}
+
+var _ repository.EventRepository = &EventRepository{}
+
+// List : always empty
+func (repo *EventRepository) List(option repository.EventListOption) ([]model.TaskEvent, error) {
+	return []model.TaskEvent{}, nil
+}
+
+// Count : always 0
+func (repo *EventRepository) Count(option repository.EventListOption) (int, error) {
+	return 0, nil
+}
+
+// One : always not found
+func (repo *EventRepository) One(id int) (*model.TaskEvent, error) {
+	return nil, domain.ErrNotFound
+}
@@ -0,0 +1,206 @@
+package fileimpl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+var _ model.TaskData = &TaskFile{}
+
+// TaskFile : tasks/<id>.json
+type TaskFile struct {
+	TaskID      int       `json:"id"`
+	TaskName    string    `json:"name"`
+	TaskNotes   string    `json:"notes,omitempty"`
+	TaskVersion int       `json:"version"`
+	TaskStartAt time.Time `json:"startAt"`
+	TaskRule    *RuleFile `json:"rule"`
+	// ordered by done at
+	History []*DoneFile `json:"history"`
+	// nil if not trashed
+	TaskDeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+func newTaskFile(id int, version int, task *model.Task, history []*DoneFile) *TaskFile {
+	if history == nil {
+		history = []*DoneFile{}
+	}
+	return &TaskFile{
+		TaskID:      id,
+		TaskName:    task.Name(),
+		TaskNotes:   task.Notes(),
+		TaskVersion: version,
+		TaskStartAt: task.StartAt(),
+		TaskRule:    newRuleFile(task.Rule()),
+		History:     history,
+	}
+}
+
+// summary : the copy having only the last done for the index
+func (file *TaskFile) summary() *TaskFile {
+	f := *file
+	f.History = []*DoneFile{}
+	if len(file.History) != 0 {
+		f.History = append(f.History, file.History[len(file.History)-1])
+	}
+	return &f
+}
+
+func (file *TaskFile) addDone(done *DoneFile) {
+	file.History = append(file.History, done)
+	sort.SliceStable(file.History, func(i, j int) bool {
+		return file.History[i].DoneAt.Before(file.History[j].DoneAt)
+	})
+}
+
+// ID :
+func (file *TaskFile) ID() int {
+	return file.TaskID
+}
+
+// Name :
+func (file *TaskFile) Name() string {
+	return file.TaskName
+}
+
+// Notes :
+func (file *TaskFile) Notes() string {
+	return file.TaskNotes
+}
+
+// Version :
+func (file *TaskFile) Version() int {
+	return file.TaskVersion
+}
+
+// Rule :
+func (file *TaskFile) Rule() *model.TaskRule {
+	return &model.TaskRule{TaskRuleData: file.TaskRule}
+}
+
+// StartAt :
+func (file *TaskFile) StartAt() time.Time {
+	return file.TaskStartAt
+}
+
+// LastDone :
+func (file *TaskFile) LastDone() *model.DoneTask {
+	if len(file.History) == 0 {
+		return nil
+	}
+	return &model.DoneTask{
+		DoneTaskData: file.History[len(file.History)-1],
+	}
+}
+
+var _ model.DoneTaskData = &DoneFile{}
+
+// DoneFile : an element of the task history
+type DoneFile struct {
+	TaskName         string     `json:"name"`
+	DoneAt           time.Time  `json:"at"`
+	DoneOccurrenceAt *time.Time `json:"occurrenceAt,omitempty"`
+}
+
+// Name :
+func (done *DoneFile) Name() string {
+	return done.TaskName
+}
+
+// At :
+func (done *DoneFile) At() time.Time {
+	return done.DoneAt
+}
+
+// OccurrenceAt :
+func (done *DoneFile) OccurrenceAt() *time.Time {
+	return done.DoneOccurrenceAt
+}
+
+var _ model.TaskRuleData = &RuleFile{}
+
+// RuleFile :
+type RuleFile struct {
+	RuleType      model.TaskRuleType `json:"type"`
+	RuleWeekdays  model.Weekdays     `json:"weekdays,omitempty"`
+	RuleDays      model.Days         `json:"days,omitempty"`
+	RuleMonthDays model.MonthDays    `json:"monthDays,omitempty"`
+	RuleDateTimes model.DateTimes    `json:"dateTimes,omitempty"`
+	RuleDates     model.Dates        `json:"dates,omitempty"`
+	RulePeriods   []PeriodFile       `json:"periods,omitempty"`
+}
+
+func newRuleFile(rule *model.TaskRule) *RuleFile {
+	periods := []PeriodFile{}
+	for _, p := range rule.Periods() {
+		periods = append(periods, PeriodFile{PeriodNumber: p.Number(), PeriodUnit: p.Unit()})
+	}
+	return &RuleFile{
+		RuleType:      rule.Type(),
+		RuleWeekdays:  append(model.Weekdays{}, rule.Weekdays()...),
+		RuleDays:      append(model.Days{}, rule.Days()...),
+		RuleMonthDays: append(model.MonthDays{}, rule.MonthDays()...),
+		RuleDateTimes: append(model.DateTimes{}, rule.DateTimes()...),
+		RuleDates:     append(model.Dates{}, rule.Dates()...),
+		RulePeriods:   periods,
+	}
+}
+
+// Type :
+func (rule *RuleFile) Type() model.TaskRuleType {
+	return rule.RuleType
+}
+
+// Weekdays :
+func (rule *RuleFile) Weekdays() model.Weekdays {
+	return append(model.Weekdays{}, rule.RuleWeekdays...)
+}
+
+// Days :
+func (rule *RuleFile) Days() model.Days {
+	return append(model.Days{}, rule.RuleDays...)
+}
+
+// MonthDays :
+func (rule *RuleFile) MonthDays() model.MonthDays {
+	return append(model.MonthDays{}, rule.RuleMonthDays...)
+}
+
+// Dates :
+func (rule *RuleFile) Dates() model.Dates {
+	return append(model.Dates{}, rule.RuleDates...)
+}
+
+// DateTimes :
+func (rule *RuleFile) DateTimes() model.DateTimes {
+	return append(model.DateTimes{}, rule.RuleDateTimes...)
+}
+
+// Periods :
+func (rule *RuleFile) Periods() model.Periods {
+	periods := model.Periods{}
+	for _, p := range rule.RulePeriods {
+		periods = append(periods, model.Period{PeriodData: p})
+	}
+	return periods
+}
+
+var _ model.PeriodData = PeriodFile{}
+
+// PeriodFile :
+type PeriodFile struct {
+	PeriodNumber int              `json:"number"`
+	PeriodUnit   model.PeriodUnit `json:"unit"`
+}
+
+// Number :
+func (period PeriodFile) Number() int {
+	return period.PeriodNumber
+}
+
+// Unit :
+func (period PeriodFile) Unit() model.PeriodUnit {
+	return period.PeriodUnit
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package fileimpl
+
+import (
+	"os"
+	"syscall"
+)
+
+// flock : blocks until locked
+func flock(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package fileimpl
+
+import (
+	"os"
+)
+
+// flock : does nothing
+// NOTE: the transactions are serialized only in the process on windows.
+func flock(f *os.File, exclusive bool) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package fileimpl
+
+import (
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/pkg/errors"
+)
+
+// Setup : directory, dependencies
+func Setup(opts ...func(*Config)) (*domain.Dep, error) {
+	config := &Config{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.DirPath == "" {
+		config.DirPath = filepath.Join(xdg.DataHome, "counteria", "files")
+	}
+
+	store := &Store{Dir: config.DirPath}
+	if err := store.setup(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &domain.Dep{
+		TaskRepository:     &TaskRepository{Store: store},
+		TransactionFactory: &TransactionFactory{Store: store},
+		BackupRepository:   &BackupRepository{},
+		EventRepository:    &EventRepository{},
+		SyncRepository:     &SyncRepository{},
+	}, nil
+}
+
+// Config :
+type Config struct {
+	DirPath string
+}
+
+// WithDirPath :
+func WithDirPath(path string) func(*Config) {
+	return func(op *Config) {
+		op.DirPath = path
+	}
+}
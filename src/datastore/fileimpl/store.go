@@ -0,0 +1,270 @@
+package fileimpl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/pkg/errors"
+)
+
+// Store : a directory having tasks/<id>.json (a task with the rule and the done history)
+// and .lock (the lock file shared between processes)
+type Store struct {
+	Dir string
+
+	// serializes transactions in the process
+	mu sync.Mutex
+}
+
+const (
+	// NOTE: written by the old versions, the index is derived from the task files now.
+	legacyIndexFileName = "index.json"
+	lockFileName        = ".lock"
+	tasksDirName        = "tasks"
+	stagingDirPattern   = ".staging-"
+)
+
+// Index : task summaries for listing, derived from the task files
+type Index struct {
+	// NOTE: the id of the last purged task may be reused.
+	LastTaskID int
+	// ordered by id
+	Tasks []*TaskFile
+}
+
+func (index *Index) put(task *TaskFile) {
+	summary := task.summary()
+	for i, t := range index.Tasks {
+		if t.TaskID == task.TaskID {
+			index.Tasks[i] = summary
+			return
+		}
+	}
+	index.Tasks = append(index.Tasks, summary)
+	sort.Slice(index.Tasks, func(i, j int) bool {
+		return index.Tasks[i].TaskID < index.Tasks[j].TaskID
+	})
+}
+
+func (index *Index) remove(taskID int) {
+	tasks := []*TaskFile{}
+	for _, t := range index.Tasks {
+		if t.TaskID != taskID {
+			tasks = append(tasks, t)
+		}
+	}
+	index.Tasks = tasks
+}
+
+func (store *Store) taskPath(taskID int) string {
+	return filepath.Join(store.Dir, tasksDirName, strconv.Itoa(taskID)+".json")
+}
+
+// lock : the shared lock for reading or the exclusive lock for writing
+// NOTE: released by closing the returned file.
+func (store *Store) lock(exclusive bool) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(store.Dir, lockFileName), os.O_RDWR|os.O_CREATE, 0660)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := flock(f, exclusive); err != nil {
+		f.Close()
+		return nil, errors.WithStack(err)
+	}
+	return f, nil
+}
+
+// shared : the shared lock for reading out of the transactions
+func (store *Store) shared() (func(), error) {
+	f, err := store.lock(false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return func() { f.Close() }, nil
+}
+
+// loadIndex : readIndex with the shared lock
+func (store *Store) loadIndex() (*Index, error) {
+	unlock, err := store.shared()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer unlock()
+	return store.readIndex()
+}
+
+// loadTask : readTask with the shared lock
+func (store *Store) loadTask(taskID int) (*TaskFile, error) {
+	unlock, err := store.shared()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer unlock()
+	return store.readTask(taskID)
+}
+
+// loadTasks : readTasks with the shared lock
+func (store *Store) loadTasks() ([]*TaskFile, error) {
+	unlock, err := store.shared()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer unlock()
+	return store.readTasks()
+}
+
+// readAll : all task files ordered by id
+// NOTE: task files may be edited or merged by hand.
+func (store *Store) readAll() ([]*TaskFile, error) {
+	taskFiles, err := ioutil.ReadDir(filepath.Join(store.Dir, tasksDirName))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tasks := []*TaskFile{}
+	for _, f := range taskFiles {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		task, err := store.readTask(id)
+		if err != nil {
+			return nil, errors.Wrap(err, f.Name())
+		}
+		if task.TaskID != id {
+			return nil, errors.Errorf("task id mismatch: %s has %d", f.Name(), task.TaskID)
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].TaskID < tasks[j].TaskID
+	})
+	return tasks, nil
+}
+
+// readIndex : derived from the task files
+func (store *Store) readIndex() (*Index, error) {
+	tasks, err := store.readAll()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	index := &Index{Tasks: []*TaskFile{}}
+	for _, task := range tasks {
+		index.Tasks = append(index.Tasks, task.summary())
+		index.LastTaskID = task.TaskID
+	}
+	return index, nil
+}
+
+func (store *Store) readTask(taskID int) (*TaskFile, error) {
+	var task TaskFile
+	if err := readJSON(store.taskPath(taskID), &task); err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &task, nil
+}
+
+// readTasks : all task files excluding the trashed
+func (store *Store) readTasks() ([]*TaskFile, error) {
+	all, err := store.readAll()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tasks := []*TaskFile{}
+	for _, task := range all {
+		if task.TaskDeletedAt == nil {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// setup : remove unfinished transactions and the legacy index
+func (store *Store) setup() error {
+	if err := os.MkdirAll(filepath.Join(store.Dir, tasksDirName), 0770); err != nil {
+		return errors.WithStack(err)
+	}
+
+	f, err := store.lock(true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	entries, err := ioutil.ReadDir(store.Dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), stagingDirPattern) {
+			if err := os.RemoveAll(filepath.Join(store.Dir, entry.Name())); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	if err := os.Remove(filepath.Join(store.Dir, legacyIndexFileName)); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func readJSON(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return errors.Wrap(err, path)
+	}
+	return nil
+}
+
+// writeJSON : write atomically by renaming a temporary file
+func writeJSON(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b = append(b, '\n')
+
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmpPath := f.Name()
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return errors.WithStack(err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return errors.WithStack(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return errors.WithStack(err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package fileimpl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// TaskRepository : impl
+type TaskRepository struct {
+	Store *Store
+}
+
+var _ repository.TaskRepository = &TaskRepository{}
+
+// List : from the index
+func (repo *TaskRepository) List(option repository.ListOption, now time.Time) ([]model.Task, error) {
+	index, err := repo.Store.loadIndex()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tasks := []model.Task{}
+	for _, t := range index.Tasks {
+		if t.TaskDeletedAt != nil {
+			continue
+		}
+		task := model.Task{TaskData: t}
+		if option.Filter.Match(task, now) {
+			tasks = append(tasks, task)
+		}
+	}
+	option.Sort.Apply(tasks, now)
+
+	start, end := option.Range(len(tasks))
+	return tasks[start:end], nil
+}
+
+// Count : ignoring limit and offset
+func (repo *TaskRepository) Count(option repository.ListOption, now time.Time) (int, error) {
+	option.Limit = 0
+	option.Offset = 0
+	tasks, err := repo.List(option, now)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return len(tasks), nil
+}
+
+// Occurrences :
+func (repo *TaskRepository) Occurrences(from time.Time, to time.Time) (model.Occurrences, error) {
+	files, err := repo.Store.loadTasks()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	occurrences := model.Occurrences{}
+	for _, file := range files {
+		dones := []model.DoneTask{}
+		for _, done := range file.History {
+			if done.DoneAt.Before(to) {
+				dones = append(dones, model.DoneTask{DoneTaskData: done})
+			}
+		}
+		task := model.Task{TaskData: file}
+		occurrences = append(occurrences, task.Occurrences(from, to, dones)...)
+	}
+	occurrences.Sort()
+
+	return occurrences, nil
+}
+
+// SearchTasks : ordered by the number of matches
+func (repo *TaskRepository) SearchTasks(words string) ([]model.SearchHit, error) {
+	files, err := repo.Store.loadTasks()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	searchWords := repository.NewSearchWords(words)
+	hits := []model.SearchHit{}
+	counts := make(map[int]int)
+	for _, file := range files {
+		task := model.Task{TaskData: file}
+		fields, count, ok := searchWords.Match(repository.SearchTexts(task)...)
+		if !ok {
+			continue
+		}
+		counts[task.ID()] = count
+		hits = append(hits, model.SearchHit{Task: task, Fields: fields})
+	}
+	repository.SortHits(hits, counts)
+	return hits, nil
+}
+
+// History : ordered by done at
+func (repo *TaskRepository) History(taskID int) ([]model.DoneTask, error) {
+	file, err := repo.Store.loadTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	history := []model.DoneTask{}
+	for _, done := range file.History {
+		history = append(history, model.DoneTask{DoneTaskData: done})
+	}
+	return history, nil
+}
+
+// ListHistory :
+func (repo *TaskRepository) ListHistory(filter repository.HistoryFilter) (model.HistoryEntries, error) {
+	files, err := repo.Store.loadTasks()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	entries := model.HistoryEntries{}
+	for _, file := range files {
+		history := []model.DoneTask{}
+		for _, done := range file.History {
+			history = append(history, model.DoneTask{DoneTaskData: done})
+		}
+		entries = append(entries, filter.Entries(model.Task{TaskData: file}, history)...)
+	}
+	entries.Sort()
+	return entries, nil
+}
+
+// Create :
+func (repo *TaskRepository) Create(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans := transaction.(*Transaction)
+
+	trans.index.LastTaskID++
+	file := newTaskFile(trans.index.LastTaskID, 1, task, nil)
+	if err := trans.put(file); err != nil {
+		return errors.WithStack(err)
+	}
+	task.TaskData = file
+
+	return nil
+}
+
+// Update : keep the history, fails if the task was changed after the version
+func (repo *TaskRepository) Update(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans := transaction.(*Transaction)
+
+	old, err := trans.sameVersion(task)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	file := newTaskFile(task.ID(), old.TaskVersion+1, task, old.History)
+	if err := trans.put(file); err != nil {
+		return errors.WithStack(err)
+	}
+	task.TaskData = file
+
+	return nil
+}
+
+// Done : and increment the version
+func (repo *TaskRepository) Done(transaction repository.Transaction, task *model.Task, now time.Time, occurrenceAt *time.Time) error {
+	trans := transaction.(*Transaction)
+
+	file, err := trans.task(task.ID())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if file.TaskDeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	file.TaskVersion++
+	file.addDone(&DoneFile{
+		TaskName:         task.Name(),
+		DoneAt:           now,
+		DoneOccurrenceAt: occurrenceAt,
+	})
+	if err := trans.put(file); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// ImportHistory : not to change the version
+func (repo *TaskRepository) ImportHistory(transaction repository.Transaction, task *model.Task, dones []model.DoneTask) (int, error) {
+	trans := transaction.(*Transaction)
+
+	file, err := trans.task(task.ID())
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	exists := make(map[int64]bool)
+	for _, done := range file.History {
+		exists[done.DoneAt.UnixNano()] = true
+	}
+
+	count := 0
+	for _, d := range dones {
+		if exists[d.At().UnixNano()] {
+			continue
+		}
+		exists[d.At().UnixNano()] = true
+		file.addDone(&DoneFile{
+			TaskName:         d.Name(),
+			DoneAt:           d.At(),
+			DoneOccurrenceAt: d.OccurrenceAt(),
+		})
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := trans.put(file); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return count, nil
+}
+
+// Delete : move to the trash
+func (repo *TaskRepository) Delete(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans := transaction.(*Transaction)
+
+	file, err := trans.sameVersion(task)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	file.TaskVersion++
+	file.TaskDeletedAt = &now
+	if err := trans.put(file); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// Trash : ordered by deleted at desc
+func (repo *TaskRepository) Trash() ([]model.TrashedTask, error) {
+	index, err := repo.Store.loadIndex()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	trashed := []model.TrashedTask{}
+	for _, t := range index.Tasks {
+		if t.TaskDeletedAt == nil {
+			continue
+		}
+		trashed = append(trashed, model.TrashedTask{
+			Task:      model.Task{TaskData: t},
+			DeletedAt: *t.TaskDeletedAt,
+		})
+	}
+	sort.SliceStable(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+	})
+	return trashed, nil
+}
+
+// Restore :
+func (repo *TaskRepository) Restore(transaction repository.Transaction, taskID int, now time.Time) error {
+	trans := transaction.(*Transaction)
+
+	file, err := trans.trashed(taskID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	file.TaskVersion++
+	file.TaskDeletedAt = nil
+	if err := trans.put(file); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// Purge : with the history
+func (repo *TaskRepository) Purge(transaction repository.Transaction, taskID int, now time.Time) error {
+	trans := transaction.(*Transaction)
+
+	if _, err := trans.trashed(taskID); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := trans.remove(taskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// PurgeBefore : from the index
+func (repo *TaskRepository) PurgeBefore(transaction repository.Transaction, at time.Time, now time.Time) (int, error) {
+	trans := transaction.(*Transaction)
+
+	ids := []int{}
+	for _, t := range trans.index.Tasks {
+		if t.TaskDeletedAt != nil && t.TaskDeletedAt.Before(at) {
+			ids = append(ids, t.TaskID)
+		}
+	}
+	for _, id := range ids {
+		if err := repo.Purge(transaction, id, now); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	return len(ids), nil
+}
+
+// One :
+func (repo *TaskRepository) One(id int) (*model.Task, error) {
+	file, err := repo.Store.loadTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if file.TaskDeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return &model.Task{TaskData: file}, nil
+}
+
+// Temporary :
+func (repo *TaskRepository) Temporary(now time.Time) *model.Task {
+	return &model.Task{TaskData: &TaskFile{
+		TaskName:    "name",
+		TaskStartAt: now,
+		TaskRule: newRuleFile(&model.TaskRule{TaskRuleData: &RuleFile{
+			RuleType:    model.TaskRuleTypePeriodic,
+			RulePeriods: []PeriodFile{{PeriodNumber: 1, PeriodUnit: model.PeriodUnitDay}},
+		}}),
+		History: []*DoneFile{},
+	}}
+}
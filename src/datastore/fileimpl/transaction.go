@@ -0,0 +1,180 @@
+package fileimpl
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+var _ repository.TransactionFactory = &TransactionFactory{}
+
+// TransactionFactory : impl
+type TransactionFactory struct {
+	Store *Store
+}
+
+// Begin : lock the store exclusively until commit or rollback
+func (factory *TransactionFactory) Begin() (repository.Transaction, error) {
+	store := factory.Store
+	store.mu.Lock()
+
+	lockFile, err := store.lock(true)
+	if err != nil {
+		store.mu.Unlock()
+		return nil, errors.WithStack(err)
+	}
+
+	index, err := store.readIndex()
+	if err != nil {
+		lockFile.Close()
+		store.mu.Unlock()
+		return nil, errors.WithStack(err)
+	}
+
+	stagingDir, err := ioutil.TempDir(store.Dir, stagingDirPattern)
+	if err != nil {
+		lockFile.Close()
+		store.mu.Unlock()
+		return nil, errors.WithStack(err)
+	}
+
+	return &Transaction{
+		store:      store,
+		lockFile:   lockFile,
+		stagingDir: stagingDir,
+		index:      index,
+		tasks:      make(map[int]*TaskFile),
+	}, nil
+}
+
+// Do : no retry because Begin waits for the lock
+func (factory *TransactionFactory) Do(work func(repository.Transaction) error, afterCommits ...func() error) error {
+	return repository.RunUnitOfWork(factory.Begin, nil, nil, work, afterCommits...)
+}
+
+var _ repository.Transaction = &Transaction{}
+
+// Transaction : changed files are written in the staging directory and renamed on commit
+type Transaction struct {
+	store      *Store
+	lockFile   *os.File
+	stagingDir string
+	index      *Index
+	// nil if deleted
+	tasks    map[int]*TaskFile
+	finished bool
+}
+
+func (trans *Transaction) stagedPath(taskID int) string {
+	return filepath.Join(trans.stagingDir, strconv.Itoa(taskID)+".json")
+}
+
+// task : the staged one if exists
+func (trans *Transaction) task(taskID int) (*TaskFile, error) {
+	if task, ok := trans.tasks[taskID]; ok {
+		if task == nil {
+			return nil, domain.ErrNotFound
+		}
+		return task, nil
+	}
+	return trans.store.readTask(taskID)
+}
+
+// sameVersion : the stored task if the version is the same, trashed one is not found
+func (trans *Transaction) sameVersion(task *model.Task) (*TaskFile, error) {
+	stored, err := trans.task(task.ID())
+	if err != nil {
+		return nil, err
+	}
+	if stored.TaskDeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	if stored.TaskVersion != task.Version() {
+		return nil, errors.Wrapf(domain.ErrConflict, "version %d is not %d", task.Version(), stored.TaskVersion)
+	}
+	return stored, nil
+}
+
+// trashed : the trashed task
+func (trans *Transaction) trashed(taskID int) (*TaskFile, error) {
+	stored, err := trans.task(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.TaskDeletedAt == nil {
+		return nil, domain.ErrNotFound
+	}
+	return stored, nil
+}
+
+func (trans *Transaction) put(task *TaskFile) error {
+	if err := writeJSON(trans.stagedPath(task.TaskID), task); err != nil {
+		return errors.WithStack(err)
+	}
+	trans.tasks[task.TaskID] = task
+	trans.index.put(task)
+	return nil
+}
+
+func (trans *Transaction) remove(taskID int) error {
+	if err := os.Remove(trans.stagedPath(taskID)); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	trans.tasks[taskID] = nil
+	trans.index.remove(taskID)
+	return nil
+}
+
+// Commit : rename the staged files
+func (trans *Transaction) Commit() error {
+	if trans.finished {
+		return errors.WithStack(sql.ErrTxDone)
+	}
+	defer trans.finish()
+
+	ids := []int{}
+	for id := range trans.tasks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		path := trans.store.taskPath(id)
+		if trans.tasks[id] == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		if err := os.Rename(trans.stagedPath(id), path); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// Rollback : discard the staged files
+func (trans *Transaction) Rollback() error {
+	if trans.finished {
+		return errors.WithStack(sql.ErrTxDone)
+	}
+	return trans.finish()
+}
+
+func (trans *Transaction) finish() error {
+	trans.finished = true
+	defer trans.store.mu.Unlock()
+	defer trans.lockFile.Close()
+	if err := os.RemoveAll(trans.stagingDir); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
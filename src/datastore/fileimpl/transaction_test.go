@@ -0,0 +1,78 @@
+package fileimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+func TestTransactionLockBetweenStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-file")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	legacyIndex := filepath.Join(dir, legacyIndexFileName)
+	if err := ioutil.WriteFile(legacyIndex, []byte(`{"lastTaskId":10,"tasks":[]}`), 0660); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// NOTE: each store has the own mutex like the other processes.
+	deps := []*TaskRepository{}
+	factories := []*TransactionFactory{}
+	for i := 0; i < 2; i++ {
+		dep, err := Setup(WithDirPath(dir))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		deps = append(deps, dep.TaskRepository.(*TaskRepository))
+		factories = append(factories, dep.TransactionFactory.(*TransactionFactory))
+	}
+	if _, err := os.Stat(legacyIndex); !os.IsNotExist(err) {
+		t.Errorf("should remove the legacy index, but: %v", err)
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	count := 10
+	var wg sync.WaitGroup
+	errs := make(chan error, len(deps)*count)
+	for i := range deps {
+		tasks := deps[i]
+		factory := factories[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				errs <- factory.Do(func(transaction repository.Transaction) error {
+					return tasks.Create(transaction, tasks.Temporary(now), now)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	index, err := deps[0].Store.loadIndex()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(index.Tasks) != len(deps)*count {
+		t.Fatalf("should create the tasks with the unique ids, but: %d", len(index.Tasks))
+	}
+	for i, task := range index.Tasks {
+		if task.TaskID != i+1 {
+			t.Errorf("should be derived from the task files, but: %d at %d", task.TaskID, i)
+		}
+	}
+}
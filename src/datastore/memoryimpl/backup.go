@@ -0,0 +1,28 @@
+package memoryimpl
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// BackupRepository : impl, nothing to back up in memory
+type BackupRepository struct{}
+
+var _ repository.BackupRepository = &BackupRepository{}
+
+// Backup : always not supported
+func (repo *BackupRepository) Backup(reason model.BackupReason) (*model.Backup, error) {
+	return nil, errors.Wrap(domain.ErrNotSupported, "backup by the memory datastore")
+}
+
+// List : always empty
+func (repo *BackupRepository) List() ([]model.Backup, error) {
+	return []model.Backup{}, nil
+}
+
+// Restore : always not found
+func (repo *BackupRepository) Restore(id int) error {
+	return domain.ErrNotFound
+}
@@ -0,0 +1,90 @@
+package memoryimpl
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+var _ model.TaskRuleData = &TaskRule{}
+
+// TaskRule :
+type TaskRule struct {
+	RuleType      model.TaskRuleType
+	RuleWeekdays  model.Weekdays
+	RuleDays      model.Days
+	RuleMonthDays model.MonthDays
+	RuleDateTimes model.DateTimes
+	RuleDates     model.Dates
+	RulePeriods   model.Periods
+}
+
+// Type :
+func (rule *TaskRule) Type() model.TaskRuleType {
+	return rule.RuleType
+}
+
+// Weekdays :
+func (rule *TaskRule) Weekdays() model.Weekdays {
+	return rule.RuleWeekdays
+}
+
+// Days :
+func (rule *TaskRule) Days() model.Days {
+	return rule.RuleDays
+}
+
+// MonthDays :
+func (rule *TaskRule) MonthDays() model.MonthDays {
+	return rule.RuleMonthDays
+}
+
+// Dates :
+func (rule *TaskRule) Dates() model.Dates {
+	return rule.RuleDates
+}
+
+// DateTimes :
+func (rule *TaskRule) DateTimes() model.DateTimes {
+	return rule.RuleDateTimes
+}
+
+// Periods :
+func (rule *TaskRule) Periods() model.Periods {
+	return rule.RulePeriods
+}
+
+// copy not to share slices with the given rule
+func readTaskRule(rule *model.TaskRule) *TaskRule {
+	periods := model.Periods{}
+	for _, p := range rule.Periods() {
+		periods = append(periods, model.Period{
+			PeriodData: Period{PeriodNumber: p.Number(), PeriodUnit: p.Unit()},
+		})
+	}
+	return &TaskRule{
+		RuleType:      rule.Type(),
+		RuleWeekdays:  append(model.Weekdays{}, rule.Weekdays()...),
+		RuleDays:      append(model.Days{}, rule.Days()...),
+		RuleMonthDays: append(model.MonthDays{}, rule.MonthDays()...),
+		RuleDateTimes: append(model.DateTimes{}, rule.DateTimes()...),
+		RuleDates:     append(model.Dates{}, rule.Dates()...),
+		RulePeriods:   periods,
+	}
+}
+
+var _ model.PeriodData = Period{}
+
+// Period :
+type Period struct {
+	PeriodNumber int
+	PeriodUnit   model.PeriodUnit
+}
+
+// Number :
+func (period Period) Number() int {
+	return period.PeriodNumber
+}
+
+// Unit :
+func (period Period) Unit() model.PeriodUnit {
+	return period.PeriodUnit
+}
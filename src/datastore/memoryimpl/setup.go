@@ -0,0 +1,17 @@
+package memoryimpl
+
+import (
+	"github.com/notomo/counteria.nvim/src/domain"
+)
+
+// Setup : dependencies, the data is lost on exit
+func Setup() *domain.Dep {
+	store := NewStore()
+	return &domain.Dep{
+		TaskRepository:     &TaskRepository{Store: store},
+		TransactionFactory: &TransactionFactory{Store: store},
+		BackupRepository:   &BackupRepository{},
+		EventRepository:    &EventRepository{},
+		SyncRepository:     &SyncRepository{},
+	}
+}
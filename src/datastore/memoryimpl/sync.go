@@ -0,0 +1,19 @@
+package memoryimpl
+
+import (
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// SyncRepository : impl, sync needs the sqlite datastore
+type SyncRepository struct{}
+
+var _ repository.SyncRepository = &SyncRepository{}
+
+// Sync : always fails
+func (repo *SyncRepository) Sync(path string, now time.Time) (*model.SyncResult, error) {
+	return nil, errors.New("sync is not supported by the memory datastore")
+}
@@ -0,0 +1,443 @@
+package memoryimpl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+// TaskRepository : impl
+type TaskRepository struct {
+	Store *Store
+}
+
+var _ repository.TaskRepository = &TaskRepository{}
+
+// List :
+func (repo *TaskRepository) List(option repository.ListOption, now time.Time) ([]model.Task, error) {
+	tasks := []model.Task{}
+	for _, task := range repo.all() {
+		if option.Filter.Match(task, now) {
+			tasks = append(tasks, task)
+		}
+	}
+	option.Sort.Apply(tasks, now)
+
+	start, end := option.Range(len(tasks))
+	return tasks[start:end], nil
+}
+
+// Count : ignoring limit and offset
+func (repo *TaskRepository) Count(option repository.ListOption, now time.Time) (int, error) {
+	count := 0
+	for _, task := range repo.all() {
+		if option.Filter.Match(task, now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// all tasks ordered by id excluding the trashed
+func (repo *TaskRepository) all() []model.Task {
+	data := repo.Store.current()
+	tasks := []model.Task{}
+	for _, task := range data.tasks {
+		if task.TaskDeletedAt != nil {
+			continue
+		}
+		tasks = append(tasks, model.Task{TaskData: data.bind(task)})
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID() < tasks[j].ID()
+	})
+	return tasks
+}
+
+// returns the copy having the last done
+func (data *snapshot) bind(task *Task) *Task {
+	t := *task
+	for _, done := range data.dones {
+		if done.TaskID != task.TaskID {
+			continue
+		}
+		if t.LastDoneTask == nil || !done.DoneAt.Before(t.LastDoneTask.DoneAt) {
+			t.LastDoneTask = done
+		}
+	}
+	return &t
+}
+
+// Occurrences :
+func (repo *TaskRepository) Occurrences(from time.Time, to time.Time) (model.Occurrences, error) {
+	data := repo.Store.current()
+	doneMap := make(map[int][]model.DoneTask)
+	for _, done := range data.dones {
+		if !done.DoneAt.Before(to) {
+			continue
+		}
+		doneMap[done.TaskID] = append(doneMap[done.TaskID], model.DoneTask{DoneTaskData: done})
+	}
+
+	occurrences := model.Occurrences{}
+	for _, task := range repo.all() {
+		occurrences = append(occurrences, task.Occurrences(from, to, doneMap[task.ID()])...)
+	}
+	occurrences.Sort()
+
+	return occurrences, nil
+}
+
+// SearchTasks : ordered by the number of matches
+func (repo *TaskRepository) SearchTasks(words string) ([]model.SearchHit, error) {
+	searchWords := repository.NewSearchWords(words)
+	hits := []model.SearchHit{}
+	counts := make(map[int]int)
+	for _, task := range repo.all() {
+		fields, count, ok := searchWords.Match(repository.SearchTexts(task)...)
+		if !ok {
+			continue
+		}
+		counts[task.ID()] = count
+		hits = append(hits, model.SearchHit{Task: task, Fields: fields})
+	}
+	repository.SortHits(hits, counts)
+	return hits, nil
+}
+
+// History : ordered by done at
+func (repo *TaskRepository) History(taskID int) ([]model.DoneTask, error) {
+	history := []model.DoneTask{}
+	for _, done := range repo.Store.current().dones {
+		if done.TaskID == taskID {
+			history = append(history, model.DoneTask{DoneTaskData: done})
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].At().Before(history[j].At())
+	})
+	return history, nil
+}
+
+// ListHistory :
+func (repo *TaskRepository) ListHistory(filter repository.HistoryFilter) (model.HistoryEntries, error) {
+	histories := make(map[int][]model.DoneTask)
+	for _, done := range repo.Store.current().dones {
+		histories[done.TaskID] = append(histories[done.TaskID], model.DoneTask{DoneTaskData: done})
+	}
+
+	entries := model.HistoryEntries{}
+	for _, task := range repo.all() {
+		history := histories[task.ID()]
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[i].At().Before(history[j].At())
+		})
+		entries = append(entries, filter.Entries(task, history)...)
+	}
+	entries.Sort()
+	return entries, nil
+}
+
+// Create :
+func (repo *TaskRepository) Create(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	data := transaction.(*Transaction).data
+
+	data.lastTaskID++
+	t := readTask(data.lastTaskID, 1, task)
+	data.tasks[t.TaskID] = t
+	task.TaskData = t
+
+	return nil
+}
+
+// Update : fails if the task was changed after the version
+func (repo *TaskRepository) Update(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	data := transaction.(*Transaction).data
+
+	old, err := data.sameVersion(task)
+	if err != nil {
+		return err
+	}
+	t := readTask(task.ID(), old.TaskVersion+1, task)
+	data.tasks[t.TaskID] = t
+	task.TaskData = data.bind(t)
+
+	return nil
+}
+
+// Done : and increment the version
+func (repo *TaskRepository) Done(transaction repository.Transaction, task *model.Task, now time.Time, occurrenceAt *time.Time) error {
+	data := transaction.(*Transaction).data
+
+	old, ok := data.tasks[task.ID()]
+	if !ok || old.TaskDeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	// NOTE: not to change the task shared with the committed data
+	t := *old
+	t.TaskVersion++
+	data.tasks[t.TaskID] = &t
+
+	data.lastDoneID++
+	data.dones = append(data.dones, &DoneTask{
+		DoneTaskID:       data.lastDoneID,
+		TaskID:           task.ID(),
+		TaskName:         task.Name(),
+		DoneAt:           now,
+		DoneOccurrenceAt: occurrenceAt,
+	})
+
+	return nil
+}
+
+// ImportHistory : not to change the version
+func (repo *TaskRepository) ImportHistory(transaction repository.Transaction, task *model.Task, dones []model.DoneTask) (int, error) {
+	data := transaction.(*Transaction).data
+
+	if _, ok := data.tasks[task.ID()]; !ok {
+		return 0, domain.ErrNotFound
+	}
+
+	exists := make(map[int64]bool)
+	for _, done := range data.dones {
+		if done.TaskID == task.ID() {
+			exists[done.DoneAt.UnixNano()] = true
+		}
+	}
+
+	count := 0
+	for _, d := range dones {
+		if exists[d.At().UnixNano()] {
+			continue
+		}
+		exists[d.At().UnixNano()] = true
+		data.lastDoneID++
+		data.dones = append(data.dones, &DoneTask{
+			DoneTaskID:       data.lastDoneID,
+			TaskID:           task.ID(),
+			TaskName:         d.Name(),
+			DoneAt:           d.At(),
+			DoneOccurrenceAt: d.OccurrenceAt(),
+		})
+		count++
+	}
+	return count, nil
+}
+
+// Delete : move to the trash
+func (repo *TaskRepository) Delete(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	data := transaction.(*Transaction).data
+
+	old, err := data.sameVersion(task)
+	if err != nil {
+		return err
+	}
+	t := *old
+	t.TaskVersion++
+	t.TaskDeletedAt = &now
+	data.tasks[t.TaskID] = &t
+
+	return nil
+}
+
+// Trash : ordered by deleted at desc
+func (repo *TaskRepository) Trash() ([]model.TrashedTask, error) {
+	data := repo.Store.current()
+	trashed := []model.TrashedTask{}
+	for _, task := range data.tasks {
+		if task.TaskDeletedAt == nil {
+			continue
+		}
+		trashed = append(trashed, model.TrashedTask{
+			Task:      model.Task{TaskData: data.bind(task)},
+			DeletedAt: *task.TaskDeletedAt,
+		})
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+	})
+	return trashed, nil
+}
+
+// trashed : the trashed task
+func (data *snapshot) trashed(taskID int) (*Task, error) {
+	task, ok := data.tasks[taskID]
+	if !ok || task.TaskDeletedAt == nil {
+		return nil, domain.ErrNotFound
+	}
+	return task, nil
+}
+
+// Restore :
+func (repo *TaskRepository) Restore(transaction repository.Transaction, taskID int, now time.Time) error {
+	data := transaction.(*Transaction).data
+
+	old, err := data.trashed(taskID)
+	if err != nil {
+		return err
+	}
+	t := *old
+	t.TaskVersion++
+	t.TaskDeletedAt = nil
+	data.tasks[t.TaskID] = &t
+
+	return nil
+}
+
+// Purge : with the done history
+func (repo *TaskRepository) Purge(transaction repository.Transaction, taskID int, now time.Time) error {
+	data := transaction.(*Transaction).data
+
+	if _, err := data.trashed(taskID); err != nil {
+		return err
+	}
+	delete(data.tasks, taskID)
+
+	dones := []*DoneTask{}
+	for _, done := range data.dones {
+		if done.TaskID != taskID {
+			dones = append(dones, done)
+		}
+	}
+	data.dones = dones
+
+	return nil
+}
+
+// PurgeBefore :
+func (repo *TaskRepository) PurgeBefore(transaction repository.Transaction, at time.Time, now time.Time) (int, error) {
+	data := transaction.(*Transaction).data
+
+	ids := []int{}
+	for id, task := range data.tasks {
+		if task.TaskDeletedAt != nil && task.TaskDeletedAt.Before(at) {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		if err := repo.Purge(transaction, id, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// One :
+func (repo *TaskRepository) One(id int) (*model.Task, error) {
+	data := repo.Store.current()
+	task, ok := data.tasks[id]
+	if !ok || task.TaskDeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return &model.Task{TaskData: data.bind(task)}, nil
+}
+
+// Temporary :
+func (repo *TaskRepository) Temporary(now time.Time) *model.Task {
+	return &model.Task{TaskData: &Task{
+		TaskName:    "name",
+		TaskStartAt: now,
+		TaskRule: readTaskRule(&model.TaskRule{TaskRuleData: &TaskRule{
+			RuleType: model.TaskRuleTypePeriodic,
+			RulePeriods: model.Periods{
+				{PeriodData: Period{PeriodNumber: 1, PeriodUnit: model.PeriodUnitDay}},
+			},
+		}}),
+	}}
+}
+
+var _ model.TaskData = &Task{}
+
+// Task :
+type Task struct {
+	TaskID      int
+	TaskName    string
+	TaskNotes   string
+	TaskVersion int
+	TaskStartAt time.Time
+	TaskRule    *TaskRule
+	// nil if not trashed
+	TaskDeletedAt *time.Time
+
+	LastDoneTask *DoneTask
+}
+
+// ID :
+func (task *Task) ID() int {
+	return task.TaskID
+}
+
+// Name :
+func (task *Task) Name() string {
+	return task.TaskName
+}
+
+// Notes :
+func (task *Task) Notes() string {
+	return task.TaskNotes
+}
+
+// Version :
+func (task *Task) Version() int {
+	return task.TaskVersion
+}
+
+// Rule :
+func (task *Task) Rule() *model.TaskRule {
+	return &model.TaskRule{TaskRuleData: task.TaskRule}
+}
+
+// StartAt :
+func (task *Task) StartAt() time.Time {
+	return task.TaskStartAt
+}
+
+// LastDone :
+func (task *Task) LastDone() *model.DoneTask {
+	if task.LastDoneTask == nil {
+		return nil
+	}
+	return &model.DoneTask{
+		DoneTaskData: task.LastDoneTask,
+	}
+}
+
+func readTask(id int, version int, task *model.Task) *Task {
+	return &Task{
+		TaskID:      id,
+		TaskName:    task.Name(),
+		TaskNotes:   task.Notes(),
+		TaskVersion: version,
+		TaskStartAt: task.StartAt(),
+		TaskRule:    readTaskRule(task.Rule()),
+	}
+}
+
+var _ model.DoneTaskData = &DoneTask{}
+
+// DoneTask :
+type DoneTask struct {
+	DoneTaskID       int
+	TaskID           int
+	TaskName         string
+	DoneAt           time.Time
+	DoneOccurrenceAt *time.Time
+}
+
+// Name :
+func (done *DoneTask) Name() string {
+	return done.TaskName
+}
+
+// At :
+func (done *DoneTask) At() time.Time {
+	return done.DoneAt
+}
+
+// OccurrenceAt :
+func (done *DoneTask) OccurrenceAt() *time.Time {
+	return done.DoneOccurrenceAt
+}
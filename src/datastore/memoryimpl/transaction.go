@@ -0,0 +1,127 @@
+package memoryimpl
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// Store : committed data
+type Store struct {
+	mu   sync.RWMutex
+	data *snapshot
+	// held by the transaction from begin until commit or rollback
+	writer sync.Mutex
+}
+
+// NewStore :
+func NewStore() *Store {
+	return &Store{
+		data: &snapshot{
+			tasks: make(map[int]*Task),
+			dones: []*DoneTask{},
+		},
+	}
+}
+
+func (store *Store) current() *snapshot {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.data
+}
+
+// NOTE: stored entities are not mutated, so a shallow copy is enough.
+type snapshot struct {
+	tasks      map[int]*Task
+	dones      []*DoneTask
+	lastTaskID int
+	lastDoneID int
+}
+
+func (data *snapshot) clone() *snapshot {
+	tasks := make(map[int]*Task, len(data.tasks))
+	for id, task := range data.tasks {
+		tasks[id] = task
+	}
+	dones := make([]*DoneTask, len(data.dones))
+	copy(dones, data.dones)
+	return &snapshot{
+		tasks:      tasks,
+		dones:      dones,
+		lastTaskID: data.lastTaskID,
+		lastDoneID: data.lastDoneID,
+	}
+}
+
+// sameVersion : the stored task if the version is the same, trashed one is not found
+func (data *snapshot) sameVersion(task *model.Task) (*Task, error) {
+	stored, ok := data.tasks[task.ID()]
+	if !ok || stored.TaskDeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	if stored.TaskVersion != task.Version() {
+		return nil, errors.Wrapf(domain.ErrConflict, "version %d is not %d", task.Version(), stored.TaskVersion)
+	}
+	return stored, nil
+}
+
+var _ repository.TransactionFactory = &TransactionFactory{}
+
+// TransactionFactory : impl
+type TransactionFactory struct {
+	Store *Store
+}
+
+// Begin : lock the store until commit or rollback, and copy the committed data
+func (factory *TransactionFactory) Begin() (repository.Transaction, error) {
+	store := factory.Store
+	store.writer.Lock()
+	return &Transaction{
+		store: store,
+		data:  store.current().clone(),
+	}, nil
+}
+
+// Do : no retry because Begin waits for the lock
+func (factory *TransactionFactory) Do(work func(repository.Transaction) error, afterCommits ...func() error) error {
+	return repository.RunUnitOfWork(factory.Begin, nil, nil, work, afterCommits...)
+}
+
+var _ repository.Transaction = &Transaction{}
+
+// Transaction : changes are visible to others after commit
+type Transaction struct {
+	store    *Store
+	data     *snapshot
+	finished bool
+}
+
+// Commit : replace the committed data
+func (trans *Transaction) Commit() error {
+	if trans.finished {
+		return errors.WithStack(sql.ErrTxDone)
+	}
+	trans.finished = true
+	defer trans.store.writer.Unlock()
+
+	trans.store.mu.Lock()
+	defer trans.store.mu.Unlock()
+	trans.store.data = trans.data
+	return nil
+}
+
+// Rollback : discard the changes
+func (trans *Transaction) Rollback() error {
+	if trans.finished {
+		return errors.WithStack(sql.ErrTxDone)
+	}
+	trans.finished = true
+	defer trans.store.writer.Unlock()
+
+	trans.data = nil
+	return nil
+}
@@ -0,0 +1,54 @@
+package memoryimpl
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+func TestTransactionFactoryOverlapping(t *testing.T) {
+	dep := Setup()
+	now := time.Now()
+
+	const count = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+				task := dep.TaskRepository.Temporary(now)
+				if err := dep.TaskRepository.Create(transaction, task, now); err != nil {
+					return err
+				}
+				// NOTE: to overlap with the other transactions
+				time.Sleep(time.Millisecond)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	tasks, err := dep.TaskRepository.List(repository.ListOption{
+		Sort: repository.Sort{By: repository.SortByTaskName, Order: repository.SortOrderAsc},
+	}, now)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	ids := make(map[int]bool)
+	for _, task := range tasks {
+		ids[task.ID()] = true
+	}
+	if len(ids) != count {
+		t.Errorf("should keep all created tasks with unique ids, but: %d tasks, %d ids", len(tasks), len(ids))
+	}
+}
@@ -0,0 +1,166 @@
+package sqliteimpl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/notomo/counteria.nvim/src/datastore/sqliteimpl/database"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// BackupRepository : impl, {Dir}/{id}-{reason}.db
+type BackupRepository struct {
+	Db    *gorp.DbMap
+	Dir   string
+	Count int
+}
+
+var _ repository.BackupRepository = &BackupRepository{}
+
+const backupExt = ".db"
+
+func (repo *BackupRepository) path(backup model.Backup) string {
+	return filepath.Join(repo.Dir, fmt.Sprintf("%d-%s%s", backup.ID, backup.Reason, backupExt))
+}
+
+// Backup : and remove the old backups of the reason
+func (repo *BackupRepository) Backup(reason model.BackupReason) (*model.Backup, error) {
+	if err := os.MkdirAll(repo.Dir, 0770); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	backups, err := repo.List()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	at := time.Now()
+	id := int(at.UnixNano() / int64(time.Millisecond))
+	// NOTE: not to collide with the backup in the same millisecond
+	if len(backups) != 0 && backups[0].ID >= id {
+		id = backups[0].ID + 1
+	}
+	backup := model.Backup{
+		ID:     id,
+		At:     at,
+		Reason: reason,
+	}
+	path := repo.path(backup)
+	if err := database.VacuumInto(repo.Db, path); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	backup.Size = info.Size()
+
+	if err := repo.rotate(reason); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &backup, nil
+}
+
+// rotate : per reason, so frequent backups do not remove the ones before destructive operations
+func (repo *BackupRepository) rotate(reason model.BackupReason) error {
+	backups, err := repo.List()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	count := 0
+	for _, backup := range backups {
+		if backup.Reason != reason {
+			continue
+		}
+		count++
+		if count <= repo.Count {
+			continue
+		}
+		if err := os.Remove(repo.path(backup)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// List : from the file names
+func (repo *BackupRepository) List() ([]model.Backup, error) {
+	files, err := ioutil.ReadDir(repo.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []model.Backup{}, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	backups := []model.Backup{}
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(name, backupExt), "-", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		backups = append(backups, model.Backup{
+			ID:     id,
+			At:     time.Unix(0, int64(id)*int64(time.Millisecond)),
+			Reason: model.BackupReason(parts[1]),
+			Size:   f.Size(),
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+
+	return backups, nil
+}
+
+// Restore : after backing up the current database
+func (repo *BackupRepository) Restore(id int) error {
+	backups, err := repo.List()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var path string
+	for _, backup := range backups {
+		if backup.ID == id {
+			path = repo.path(backup)
+		}
+	}
+	if path == "" {
+		return domain.ErrNotFound
+	}
+
+	version, err := database.FileVersion(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if version != migrations.Version() {
+		return errors.Errorf("the backup schema version %d is not the current version %d", version, migrations.Version())
+	}
+
+	if _, err := repo.Backup(model.BackupReasonRestore); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return database.RestoreFrom(repo.Db, path)
+}
@@ -0,0 +1,69 @@
+package sqliteimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+func TestBackupRepositoryRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-backup")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	backupPath := filepath.Join(dir, "backups")
+	dep, err := Setup(
+		WithDataPath(filepath.Join(dir, "test.db")),
+		WithBackupCount(2),
+		WithBackupPath(backupPath),
+	)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	repo := dep.BackupRepository
+
+	for _, reason := range []model.BackupReason{
+		model.BackupReasonImport,
+		model.BackupReasonStart,
+		model.BackupReasonStart,
+		model.BackupReasonStart,
+		model.BackupReasonStart,
+	} {
+		if _, err := repo.Backup(reason); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	backups, err := repo.List()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	counts := make(map[model.BackupReason]int)
+	ids := make(map[int]bool)
+	for _, backup := range backups {
+		counts[backup.Reason]++
+		ids[backup.ID] = true
+	}
+	if counts[model.BackupReasonImport] != 1 {
+		t.Errorf("should keep the import backup, but: %v", counts)
+	}
+	if counts[model.BackupReasonStart] != 2 {
+		t.Errorf("should keep 2 start backups, but: %v", counts)
+	}
+	if len(ids) != len(backups) {
+		t.Errorf("should have unique ids, but: %v", backups)
+	}
+
+	dirs, err := ioutil.ReadDir(backupPath)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(dirs) != 1 {
+		t.Errorf("should back up into the backup path, but: %v", dirs)
+	}
+}
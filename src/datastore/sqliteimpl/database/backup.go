@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-gorp/gorp"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// VacuumInto : write a compacted copy of the database to the path
+// NOTE: the path must not exist.
+func VacuumInto(dbmap *gorp.DbMap, path string) error {
+	if _, err := dbmap.Exec(`VACUUM INTO ?`, path); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// RestoreFrom : overwrite the database by the database file with the online backup API
+func RestoreFrom(dbmap *gorp.DbMap, path string) error {
+	src, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer src.Close()
+
+	ctx := context.Background()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer srcConn.Close()
+
+	destConn, err := dbmap.Db.Conn(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(dest interface{}) error {
+		return srcConn.Raw(func(src interface{}) error {
+			backup, err := dest.(*sqlite3.SQLiteConn).Backup("main", src.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return errors.WithStack(err)
+			}
+			if err := backup.Finish(); err != nil {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+	})
+}
+
+// FileVersion : the schema version of the database file
+func FileVersion(path string) (int, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer db.Close()
+
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM ` + versionTable).Scan(&version); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int(version.Int64), nil
+}
@@ -110,4 +110,11 @@ var checkFuncs = map[string]func(string) string{
 		}
 		return fmt.Sprintf(`%s IN (%s)`, column, strings.Join(enums, ", "))
 	},
+	"taskEventType": func(column string) string {
+		enums := []string{}
+		for _, e := range model.TaskEventTypes() {
+			enums = append(enums, fmt.Sprintf(`"%s"`, e))
+		}
+		return fmt.Sprintf(`%s IN (%s)`, column, strings.Join(enums, ", "))
+	},
 }
@@ -1,29 +1,28 @@
 package database
 
 import (
+	"crypto/sha1"
 	"database/sql"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/go-gorp/gorp"
 	"github.com/pkg/errors"
 )
 
-// Setup : database file, tables
-func Setup(tables Tables, config *Config) (*gorp.DbMap, error) {
-	var dbPath string
-	if config.DataPath == "" {
-		dbDirPath := filepath.Join(xdg.DataHome, "counteria")
-		if err := os.MkdirAll(dbDirPath, 0770); err != nil {
-			return nil, errors.WithStack(err)
-		}
-		dbPath = filepath.Join(dbDirPath, "default.db")
-	} else {
-		dbPath = config.DataPath
+// Setup : database file, migrations, tables
+func Setup(tables Tables, migrations Migrations, config *Config) (*gorp.DbMap, error) {
+	dbPath, err := config.Path()
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open("sqlite3", dsn(dbPath))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -31,6 +30,10 @@ func Setup(tables Tables, config *Config) (*gorp.DbMap, error) {
 	dbmap := &gorp.DbMap{Db: db, Dialect: gorp.SqliteDialect{}}
 	dbmap.ExpandSliceArgs = true
 
+	if err := migrations.Migrate(dbmap, tables, dbPath); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	if err := tables.Setup(dbmap); err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -38,7 +41,55 @@ func Setup(tables Tables, config *Config) (*gorp.DbMap, error) {
 	return dbmap, nil
 }
 
+// BusyTimeout : how long to wait for the lock held by another process
+const BusyTimeout = 2 * time.Second
+
+// NOTE: WAL allows reading while another process is writing.
+// Immediate transactions take the write lock on begin, so lock contention is detected before any change.
+func dsn(dbPath string) string {
+	params := url.Values{}
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", strconv.Itoa(int(BusyTimeout/time.Millisecond)))
+	params.Set("_txlock", "immediate")
+	return dbPath + "?" + params.Encode()
+}
+
 // Config :
 type Config struct {
 	DataPath string
+	// the number of backups to keep per reason
+	BackupCount int
+	// the directory having the backups of the database files
+	BackupPath string
+}
+
+// DefaultBackupCount :
+const DefaultBackupCount = 10
+
+// Path : the database file path, default.db in the xdg data directory if not specified
+func (config *Config) Path() (string, error) {
+	if config.DataPath != "" {
+		return config.DataPath, nil
+	}
+
+	dbDirPath := filepath.Join(xdg.DataHome, "counteria")
+	if err := os.MkdirAll(dbDirPath, 0770); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Join(dbDirPath, "default.db"), nil
+}
+
+// BackupDir : {database file name}-{hash of the path} in counteria/backups of the xdg data directory if not specified
+// NOTE: the hash distinguishes the database files having the same name.
+func (config *Config) BackupDir(dbPath string) (string, error) {
+	root := config.BackupPath
+	if root == "" {
+		root = filepath.Join(xdg.DataHome, "counteria", "backups")
+	}
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	hash := sha1.Sum([]byte(abs))
+	return filepath.Join(root, fmt.Sprintf("%s-%x", filepath.Base(dbPath), hash[:4])), nil
 }
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+	"github.com/pkg/errors"
+)
+
+// Index :
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// SQL : to create the index on the table if not exists
+func (index Index) SQL(table string) string {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s)", unique, index.Name, table, strings.Join(index.Columns, ", "))
+}
+
+// Indexes :
+type Indexes []Index
+
+func (indexes Indexes) create(dbmap *gorp.DbMap, table string) error {
+	for _, index := range indexes {
+		if _, err := dbmap.Exec(index.SQL(table)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
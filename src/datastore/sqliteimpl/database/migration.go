@@ -0,0 +1,142 @@
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-gorp/gorp"
+	"github.com/pkg/errors"
+)
+
+// Migration : a schema change from the previous version
+type Migration struct {
+	Name string
+	Up   func(trans *gorp.Transaction) error
+}
+
+// Migrations : ordered, the version after applying migrations[i] is i + 1
+type Migrations []Migration
+
+// Version : the latest schema version
+func (migrations Migrations) Version() int {
+	return len(migrations)
+}
+
+// ErrNewerSchema : the database was migrated by a newer version
+var ErrNewerSchema = fmt.Errorf("newer schema")
+
+const versionTable = "schema_version"
+
+// Migrate : apply migrations to the existing database and record the schema version
+func (migrations Migrations) Migrate(dbmap *gorp.DbMap, tables Tables, dbPath string) error {
+	if _, err := dbmap.Exec(`CREATE TABLE IF NOT EXISTS ` + versionTable + ` (version integer not null)`); err != nil {
+		return errors.WithStack(err)
+	}
+
+	latest := migrations.Version()
+	current, recorded, err := migrations.current(dbmap, tables)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if current > latest {
+		return errors.Wrapf(ErrNewerSchema, "schema version %d is newer than the supported version %d", current, latest)
+	}
+	if current == latest {
+		if recorded {
+			return nil
+		}
+		return saveVersion(dbmap, latest)
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", dbPath, current)
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	// NOTE: copying the file misses the changes in the WAL file.
+	if err := VacuumInto(dbmap, backupPath); err != nil {
+		return errors.WithStack(err)
+	}
+
+	trans, err := dbmap.Begin()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, migration := range migrations[current:] {
+		if err := migration.Up(trans); err != nil {
+			if err := trans.Rollback(); err != nil {
+				return errors.WithStack(err)
+			}
+			return errors.Wrapf(err, "migration: %s (backup: %s)", migration.Name, backupPath)
+		}
+	}
+	if err := saveVersion(trans, latest); err != nil {
+		if err := trans.Rollback(); err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(err)
+	}
+	if err := trans.Commit(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// NOTE: a database without the version is the latest if it has no tables, otherwise the first
+func (migrations Migrations) current(dbmap *gorp.DbMap, tables Tables) (int, bool, error) {
+	version, err := dbmap.SelectNullInt(`SELECT MAX(version) FROM ` + versionTable)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	if version.Valid {
+		return int(version.Int64), true, nil
+	}
+
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+	}
+	count, err := dbmap.SelectInt(`
+	SELECT COUNT(*)
+	FROM sqlite_master
+	WHERE type = 'table'
+	AND name IN (:names)
+	`, map[string]interface{}{"names": names})
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	if count == 0 {
+		return migrations.Version(), false, nil
+	}
+	return 0, false, nil
+}
+
+func saveVersion(exe gorp.SqlExecutor, version int) error {
+	if _, err := exe.Exec(`DELETE FROM ` + versionTable); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := exe.Exec(`INSERT INTO `+versionTable+` (version) VALUES (?)`, version); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// AddColumn : add the column if not exists
+func AddColumn(trans *gorp.Transaction, table string, column string, definition string) error {
+	var columns []struct {
+		Name string `db:"name"`
+	}
+	if _, err := trans.Select(&columns, `SELECT name FROM pragma_table_info(?)`, table); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, c := range columns {
+		if c.Name == column {
+			return nil
+		}
+	}
+
+	if _, err := trans.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
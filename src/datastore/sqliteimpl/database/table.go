@@ -14,6 +14,9 @@ type Table struct {
 	Base      interface{}
 	Name      string
 	RawChecks []string
+	// the column for optimistic locking if not empty
+	VersionColumn string
+	Indexes       Indexes
 }
 
 var sqlSuffix = regexp.MustCompile(`\)\s*;$`)
@@ -32,12 +35,20 @@ func (table *Table) Create(dbmap *gorp.DbMap) error {
 
 	sqlParts := checks.String() + foreignKeys.String()
 	ifNotExists := true
-	baseSQL := dbmap.AddTableWithName(table.Base, table.Name).SqlForCreate(ifNotExists)
+	tableMap := dbmap.AddTableWithName(table.Base, table.Name)
+	if table.VersionColumn != "" {
+		tableMap.SetVersionCol(table.VersionColumn)
+	}
+	baseSQL := tableMap.SqlForCreate(ifNotExists)
 	sql := sqlSuffix.ReplaceAllString(baseSQL, sqlParts+") ;")
 	if _, err := dbmap.Exec(sql); err != nil {
 		return errors.WithStack(err)
 	}
 
+	if err := table.Indexes.create(dbmap, table.Name); err != nil {
+		return errors.WithStack(err)
+	}
+
 	return nil
 }
 
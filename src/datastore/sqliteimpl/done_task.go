@@ -6,6 +6,7 @@ import (
 	"github.com/go-gorp/gorp"
 	"github.com/notomo/counteria.nvim/src/domain/model"
 	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/lib"
 	"github.com/pkg/errors"
 )
 
@@ -15,24 +16,57 @@ type DoneTaskRepository struct {
 }
 
 // Create :
-func (repo *DoneTaskRepository) Create(transaction repository.Transaction, task *model.Task, now time.Time) error {
-	trans := transaction.(*gorp.Transaction)
+func (repo *DoneTaskRepository) Create(transaction repository.Transaction, task *model.Task, now time.Time, occurrenceAt *time.Time) error {
+	trans := gorpTransaction(transaction)
 
 	done := DoneTask{
-		TaskID:   task.ID(),
-		TaskName: task.Name(),
-		DoneAt:   now,
+		UUID:             lib.NewUUID(),
+		TaskID:           task.ID(),
+		TaskName:         task.Name(),
+		DoneAt:           now,
+		DoneOccurrenceAt: occurrenceAt,
 	}
 	if err := trans.Insert(&done); err != nil {
 		return errors.WithStack(err)
 	}
 
+	if err := repo.RefreshLast(transaction, task.ID()); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// RefreshLast : update the task's cache of the latest done and the deadline by it
+func (repo *DoneTaskRepository) RefreshLast(transaction repository.Transaction, taskID int) error {
+	trans := gorpTransaction(transaction)
+
+	if _, err := trans.Exec(`
+	UPDATE tasks
+	SET (last_done_id, last_done_at, last_done_occurrence_at) = (
+		SELECT
+			id
+			,at
+			,occurrence_at
+		FROM done_tasks
+		WHERE task_id = :id
+		ORDER BY at DESC, id DESC
+		LIMIT 1
+	)
+	WHERE id = :id
+	`, map[string]interface{}{"id": taskID}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := refreshDeadlines(trans, "WHERE t.id = :id", map[string]interface{}{"id": taskID}); err != nil {
+		return errors.WithStack(err)
+	}
 	return nil
 }
 
 // Delete :
 func (repo *DoneTaskRepository) Delete(transaction repository.Transaction, taskID int) error {
-	trans := transaction.(*gorp.Transaction)
+	trans := gorpTransaction(transaction)
 
 	dones, err := repo.List(taskID)
 	if err != nil {
@@ -57,23 +91,75 @@ func (repo *DoneTaskRepository) List(taskID int) ([]DoneTask, error) {
 	SELECT *
 	FROM done_tasks
 	WHERE task_id = ?
+	ORDER BY at
 	`, taskID); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return dones, nil
 }
 
+// ListForOccurrences : the active tasks' dones needed to expand the occurrences in [from, to)
+// the dones in the window, linked to the occurrences in the window, and the last not linked one before the window
+func (repo *DoneTaskRepository) ListForOccurrences(from time.Time, to time.Time) ([]DoneTask, error) {
+	dones := []DoneTask{}
+	if _, err := repo.Db.Select(&dones, `
+	SELECT d.*
+	FROM tasks t
+	JOIN done_tasks d ON d.task_id = t.id
+	WHERE t.deleted_at IS NULL
+	AND d.at >= :from
+	AND d.at < :to
+	UNION ALL
+	SELECT d.*
+	FROM tasks t
+	JOIN done_tasks d ON d.task_id = t.id
+	WHERE t.deleted_at IS NULL
+	AND d.occurrence_at >= :from
+	AND d.occurrence_at < :to
+	AND d.at < :from
+	UNION ALL
+	SELECT d.*
+	FROM tasks t
+	JOIN done_tasks d ON d.id = (
+		SELECT id
+		FROM done_tasks
+		WHERE task_id = t.id
+		AND at < :from
+		AND occurrence_at IS NULL
+		ORDER BY at DESC, id DESC
+		LIMIT 1
+	)
+	WHERE t.deleted_at IS NULL
+	`, map[string]interface{}{"from": from, "to": to}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return dones, nil
+}
+
 var _ model.DoneTaskData = &DoneTask{}
 
 // DoneTask :
 type DoneTask struct {
-	DoneTaskID int       `db:"id, primarykey, autoincrement"`
-	TaskID     int       `db:"task_id, notnull" foreign:"tasks(id)"`
-	TaskName   string    `db:"name, notnull" check:"notEmpty"`
-	DoneAt     time.Time `db:"at, notnull"`
+	DoneTaskID       int        `db:"id, primarykey, autoincrement"`
+	TaskID           int        `db:"task_id, notnull" foreign:"tasks(id)"`
+	TaskName         string     `db:"name, notnull" check:"notEmpty"`
+	DoneAt           time.Time  `db:"at, notnull"`
+	DoneOccurrenceAt *time.Time `db:"occurrence_at"`
+	// identifies the done across the database copies
+	UUID string `db:"uuid, notnull, size:36"`
+}
+
+// Name :
+func (done *DoneTask) Name() string {
+	return done.TaskName
 }
 
 // At :
 func (done *DoneTask) At() time.Time {
 	return done.DoneAt
 }
+
+// OccurrenceAt :
+func (done *DoneTask) OccurrenceAt() *time.Time {
+	return done.DoneOccurrenceAt
+}
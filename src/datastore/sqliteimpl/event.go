@@ -0,0 +1,203 @@
+package sqliteimpl
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// EventRepository : impl, appended by TaskRepository in the same transaction
+type EventRepository struct {
+	Db    *gorp.DbMap
+	Actor string
+}
+
+var _ repository.EventRepository = &EventRepository{}
+
+// Setup : forbid changing the events
+func (repo *EventRepository) Setup() error {
+	for _, op := range []string{"UPDATE", "DELETE"} {
+		if _, err := repo.Db.Exec(`
+		CREATE TRIGGER IF NOT EXISTS task_events_no_` + op + `
+		BEFORE ` + op + ` ON task_events
+		BEGIN
+			SELECT RAISE(ABORT, 'task_events is append only');
+		END
+		`); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// TaskSnapshot : the task in the event
+type TaskSnapshot struct {
+	Name       string             `json:"name"`
+	Notes      string             `json:"notes"`
+	Version    int                `json:"version"`
+	StartAt    time.Time          `json:"startAt"`
+	RuleType   model.TaskRuleType `json:"ruleType"`
+	RuleLines  []TaskRuleLine     `json:"ruleLines"`
+	LastDoneAt *time.Time         `json:"lastDoneAt,omitempty"`
+	DeletedAt  *time.Time         `json:"deletedAt,omitempty"`
+}
+
+// snapshot : the task in the event, the rule lines by the bound rule
+func (task *Task) snapshot() *TaskSnapshot {
+	return &TaskSnapshot{
+		Name:       task.TaskName,
+		Notes:      task.TaskNotes,
+		Version:    task.TaskVersion,
+		StartAt:    task.TaskStartAt,
+		RuleType:   task.TaskRuleType,
+		RuleLines:  task.ruleLines(),
+		LastDoneAt: task.LastDoneAt,
+		DeletedAt:  task.DeletedAt,
+	}
+}
+
+// Append : the event from the snapshot before the change to the snapshot after the change, nil if not exists
+func (repo *EventRepository) Append(transaction repository.Transaction, typ model.TaskEventType, taskID int, before *TaskSnapshot, after *TaskSnapshot, now time.Time) error {
+	trans := gorpTransaction(transaction)
+
+	event := TaskEvent{
+		TaskID:    taskID,
+		EventType: typ,
+		At:        now,
+		Actor:     repo.Actor,
+	}
+	for _, s := range []struct {
+		snapshot *TaskSnapshot
+		json     **string
+	}{
+		{snapshot: before, json: &event.Before},
+		{snapshot: after, json: &event.After},
+	} {
+		if s.snapshot == nil {
+			continue
+		}
+		b, err := json.Marshal(s.snapshot)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		str := string(b)
+		*s.json = &str
+		event.TaskName = s.snapshot.Name
+	}
+
+	if err := trans.Insert(&event); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// List : newest first
+func (repo *EventRepository) List(option repository.EventListOption) ([]model.TaskEvent, error) {
+	where, args := convertEventListOption(option)
+	if option.Limit > 0 {
+		where += " LIMIT :limit OFFSET :offset"
+		args["limit"] = option.Limit
+		args["offset"] = option.Offset
+	}
+
+	rows := []TaskEvent{}
+	if _, err := repo.Db.Select(&rows, `
+	SELECT *
+	FROM task_events
+	`+where, args); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	events := make([]model.TaskEvent, len(rows))
+	for i, row := range rows {
+		events[i] = row.model()
+	}
+	return events, nil
+}
+
+// Count : ignoring limit and offset
+func (repo *EventRepository) Count(option repository.EventListOption) (int, error) {
+	where, args := convertEventListOption(option)
+	count, err := repo.Db.SelectInt(`
+	SELECT COUNT(*)
+	FROM task_events
+	`+where, args)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int(count), nil
+}
+
+func convertEventListOption(option repository.EventListOption) (string, map[string]interface{}) {
+	where := ""
+	args := map[string]interface{}{}
+	if option.TaskID != 0 {
+		where = "WHERE task_id = :taskId"
+		args["taskId"] = option.TaskID
+	}
+	return where + " ORDER BY id DESC", args
+}
+
+// One :
+func (repo *EventRepository) One(id int) (*model.TaskEvent, error) {
+	var row TaskEvent
+	if err := repo.Db.SelectOne(&row, `SELECT * FROM task_events WHERE id = ?`, id); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+	event := row.model()
+	return &event, nil
+}
+
+// TaskEvent : the task id is not a foreign key to keep the events of the deleted tasks
+type TaskEvent struct {
+	EventID   int                 `db:"id, primarykey, autoincrement"`
+	TaskID    int                 `db:"task_id, notnull"`
+	TaskName  string              `db:"name, notnull"`
+	EventType model.TaskEventType `db:"type, notnull" check:"taskEventType"`
+	At        time.Time           `db:"at, notnull"`
+	Actor     string              `db:"actor, notnull"`
+	Before    *string             `db:"before_json"`
+	After     *string             `db:"after_json"`
+}
+
+func (event TaskEvent) model() model.TaskEvent {
+	e := model.TaskEvent{
+		ID:       event.EventID,
+		TaskID:   event.TaskID,
+		TaskName: event.TaskName,
+		Type:     event.EventType,
+		At:       event.At,
+		Actor:    event.Actor,
+	}
+	if event.Before != nil {
+		e.Before = *event.Before
+	}
+	if event.After != nil {
+		e.After = *event.After
+	}
+	return e
+}
+
+// currentActor : user@host of this process
+func currentActor() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return name
+	}
+	return name + "@" + host
+}
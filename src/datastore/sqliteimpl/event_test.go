@@ -0,0 +1,59 @@
+package sqliteimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+func TestEventRepositoryAt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-event")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dep, err := Setup(WithDataPath(filepath.Join(dir, "test.db")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	doneAt := createdAt.AddDate(0, 0, 1)
+	deletedAt := createdAt.AddDate(0, 0, 2)
+	task := dep.TaskRepository.Temporary(createdAt)
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		if err := dep.TaskRepository.Create(transaction, task, createdAt); err != nil {
+			return err
+		}
+		if err := dep.TaskRepository.Done(transaction, task, doneAt, nil); err != nil {
+			return err
+		}
+		stored, err := dep.TaskRepository.(*TaskRepository).stored(gorpTransaction(transaction), task.ID())
+		if err != nil {
+			return err
+		}
+		task.TaskData = stored
+		return dep.TaskRepository.Delete(transaction, task, deletedAt)
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	events, err := dep.EventRepository.List(repository.EventListOption{TaskID: task.ID()})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	want := []time.Time{deletedAt, doneAt, createdAt}
+	if len(events) != len(want) {
+		t.Fatalf("should have %d events, but: %v", len(want), events)
+	}
+	for i, event := range events {
+		if !event.At.Equal(want[i]) {
+			t.Errorf("%s event should be at %s, but: %s", event.Type, want[i], event.At)
+		}
+	}
+}
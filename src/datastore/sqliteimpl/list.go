@@ -2,24 +2,102 @@ package sqliteimpl
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/notomo/counteria.nvim/src/domain/repository"
 )
 
-func convertListOption(option repository.ListOption) string {
-	// TODO : limit, offset
-	return convertSort(option.Sort)
+// returns sql, args and whether the sql slices rows
+func convertListOption(option repository.ListOption) (string, map[string]interface{}, bool) {
+	where, args := convertFilter(option.Filter)
+	sql := where + convertSort(option.Sort)
+	if option.Filter.HasState() {
+		// NOTE: filtered and sliced after selecting
+		return sql, args, false
+	}
+	return sql + convertLimit(option.Limit, option.Offset), args, true
+}
+
+// NOTE: Active, Overdue, Done are not supported. trashed tasks are excluded.
+func convertFilter(filter repository.Filter) (string, map[string]interface{}) {
+	conditions := []string{"t.deleted_at IS NULL"}
+	args := map[string]interface{}{}
+	if len(filter.RuleTypes) != 0 {
+		types := make([]string, len(filter.RuleTypes))
+		for i, typ := range filter.RuleTypes {
+			types[i] = typ.String()
+		}
+		conditions = append(conditions, "t.rule_type IN (:ruleTypes)")
+		args["ruleTypes"] = types
+	}
+	if filter.Name != "" {
+		conditions = append(conditions, `t.name_lower LIKE :name ESCAPE '\'`)
+		args["name"] = "%" + likeEscaper.Replace(strings.ToLower(filter.Name)) + "%"
+	}
+	if filter.StartFrom != nil {
+		conditions = append(conditions, "t.start_at >= :startFrom")
+		args["startFrom"] = *filter.StartFrom
+	}
+	if filter.StartTo != nil {
+		conditions = append(conditions, "t.start_at < :startTo")
+		args["startTo"] = *filter.StartTo
+	}
+
+	return "\n\tWHERE " + strings.Join(conditions, "\n\tAND "), args
 }
 
+// returns the conditions of the tasks and the dones
+// NOTE: trashed tasks are excluded.
+func convertHistoryFilter(filter repository.HistoryFilter) (string, string, map[string]interface{}) {
+	taskConditions := []string{"t.deleted_at IS NULL"}
+	doneConditions := []string{}
+	args := map[string]interface{}{}
+	if len(filter.TaskIDs) != 0 {
+		taskConditions = append(taskConditions, "t.id IN (:taskIds)")
+		args["taskIds"] = filter.TaskIDs
+	}
+	if filter.From != nil {
+		doneConditions = append(doneConditions, "d.at >= :from")
+		args["from"] = *filter.From
+	}
+	if filter.To != nil {
+		doneConditions = append(doneConditions, "d.at < :to")
+		args["to"] = *filter.To
+	}
+
+	taskWhere := "\n\tWHERE " + strings.Join(taskConditions, "\n\tAND ")
+	doneWhere := ""
+	for _, condition := range doneConditions {
+		doneWhere += "\n\tAND " + condition
+	}
+	return taskWhere, doneWhere, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func convertSort(sort repository.Sort) string {
 	var by string
 	switch sort.By {
 	case repository.SortByTaskDoneAt:
-		by = "done.at"
+		by = "t.last_done_at"
+	case repository.SortByTaskName:
+		by = "t.name"
 	case repository.SortByTaskRemains:
-		return ""
+		// NOTE: no deadline is later than any deadline
+		return fmt.Sprintf("\n\tORDER BY t.deadline_at IS NULL %s, t.deadline_at %s", sort.Order, sort.Order)
 	default:
 		panic("invalid sort by: " + sort.By)
 	}
-	return fmt.Sprintf("ORDER BY %s %s", by, sort.Order)
+	return fmt.Sprintf("\n\tORDER BY %s %s", by, sort.Order)
+}
+
+func convertLimit(limit int, offset int) string {
+	if limit <= 0 {
+		if offset <= 0 {
+			return ""
+		}
+		// NOTE: OFFSET requires LIMIT in sqlite
+		limit = -1
+	}
+	return fmt.Sprintf("\n\tLIMIT %d OFFSET %d", limit, offset)
 }
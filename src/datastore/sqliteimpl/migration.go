@@ -0,0 +1,277 @@
+package sqliteimpl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/notomo/counteria.nvim/src/datastore/sqliteimpl/database"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/lib"
+	"github.com/pkg/errors"
+)
+
+// NOTE: append only, the index + 1 is the schema version
+// The migrations select the columns at the version, not by the current structs.
+var migrations = database.Migrations{
+	{
+		Name: "add done_tasks.occurrence_at",
+		Up: func(trans *gorp.Transaction) error {
+			return database.AddColumn(trans, "done_tasks", "occurrence_at", "datetime")
+		},
+	},
+	{
+		Name: "add tasks.notes",
+		Up: func(trans *gorp.Transaction) error {
+			return database.AddColumn(trans, "tasks", "notes", "varchar(255) not null default ''")
+		},
+	},
+	{
+		Name: "add tasks.version",
+		Up: func(trans *gorp.Transaction) error {
+			return database.AddColumn(trans, "tasks", "version", "integer not null default 1")
+		},
+	},
+	{
+		Name: "add tasks.deleted_at",
+		Up: func(trans *gorp.Transaction) error {
+			return database.AddColumn(trans, "tasks", "deleted_at", "datetime")
+		},
+	},
+	{
+		Name: "add uuids and modification times for sync",
+		Up: func(trans *gorp.Transaction) error {
+			for _, c := range []struct {
+				table      string
+				column     string
+				definition string
+			}{
+				{table: "tasks", column: "uuid", definition: "varchar(36) not null default ''"},
+				{table: "tasks", column: "updated_at", definition: "datetime not null default '1970-01-01 00:00:00'"},
+				{table: "tasks", column: "synced_at", definition: "datetime"},
+				{table: "done_tasks", column: "uuid", definition: "varchar(36) not null default ''"},
+			} {
+				if err := database.AddColumn(trans, c.table, c.column, c.definition); err != nil {
+					return err
+				}
+			}
+			return migrateUUIDs(trans)
+		},
+	},
+	{
+		Name: "cache the last done in tasks",
+		Up: func(trans *gorp.Transaction) error {
+			for _, c := range []struct {
+				column     string
+				definition string
+			}{
+				{column: "last_done_id", definition: "integer"},
+				{column: "last_done_at", definition: "datetime"},
+				{column: "last_done_occurrence_at", definition: "datetime"},
+			} {
+				if err := database.AddColumn(trans, "tasks", c.column, c.definition); err != nil {
+					return err
+				}
+			}
+			return migrateLastDones(trans)
+		},
+	},
+	{
+		Name: "cache the deadline in tasks",
+		Up: func(trans *gorp.Transaction) error {
+			if err := database.AddColumn(trans, "tasks", "deadline_at", "datetime"); err != nil {
+				return err
+			}
+			return migrateDeadlines(trans)
+		},
+	},
+	{
+		Name: "add tasks.name_lower",
+		Up: func(trans *gorp.Transaction) error {
+			if err := database.AddColumn(trans, "tasks", "name_lower", "varchar(255) not null default ''"); err != nil {
+				return err
+			}
+			return migrateNameLowers(trans)
+		},
+	},
+}
+
+// migrateUUIDs : for the rows before the uuid columns
+// NOTE: derived from the rows to give the same uuids to the copies of a database.
+func migrateUUIDs(trans *gorp.Transaction) error {
+	var tasks []struct {
+		ID      int       `db:"id"`
+		StartAt time.Time `db:"start_at"`
+	}
+	if _, err := trans.Select(&tasks, `SELECT id, start_at FROM tasks`); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, t := range tasks {
+		uuid := lib.NameUUID(fmt.Sprintf("task:%d:%d", t.ID, t.StartAt.UnixNano()))
+		if _, err := trans.Exec(`UPDATE tasks SET uuid = ?, updated_at = start_at WHERE id = ?`, uuid, t.ID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	var dones []struct {
+		ID       int       `db:"id"`
+		At       time.Time `db:"at"`
+		TaskUUID string    `db:"task_uuid"`
+	}
+	if _, err := trans.Select(&dones, `
+	SELECT
+		d.id
+		,d.at
+		,t.uuid AS task_uuid
+	FROM done_tasks d
+	JOIN tasks t ON t.id = d.task_id
+	`); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, d := range dones {
+		uuid := lib.NameUUID(fmt.Sprintf("done:%s:%d:%d", d.TaskUUID, d.ID, d.At.UnixNano()))
+		if _, err := trans.Exec(`UPDATE done_tasks SET uuid = ? WHERE id = ?`, uuid, d.ID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// migrateLastDones : for the tasks before the last done columns
+func migrateLastDones(trans *gorp.Transaction) error {
+	var lasts []struct {
+		TaskID int `db:"task_id"`
+		ID     int `db:"id"`
+	}
+	if _, err := trans.Select(&lasts, `
+	SELECT
+		d.task_id
+		,MAX(d.id) AS id
+	FROM done_tasks d
+	JOIN (
+		SELECT task_id, MAX(at) AS at
+		FROM done_tasks
+		GROUP BY task_id
+	) last ON last.task_id = d.task_id AND last.at = d.at
+	GROUP BY d.task_id
+	`); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, last := range lasts {
+		if _, err := trans.Exec(`
+		UPDATE tasks
+		SET (last_done_id, last_done_at, last_done_occurrence_at) = (
+			SELECT
+				id
+				,at
+				,occurrence_at
+			FROM done_tasks
+			WHERE id = :doneId
+		)
+		WHERE id = :taskId
+		`, map[string]interface{}{"doneId": last.ID, "taskId": last.TaskID}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// migrateDeadlines : for the tasks before the deadline column
+func migrateDeadlines(trans *gorp.Transaction) error {
+	var tasks []struct {
+		ID                   int                `db:"id"`
+		StartAt              time.Time          `db:"start_at"`
+		RuleType             model.TaskRuleType `db:"rule_type"`
+		LastDoneID           *int               `db:"last_done_id"`
+		LastDoneAt           *time.Time         `db:"last_done_at"`
+		LastDoneOccurrenceAt *time.Time         `db:"last_done_occurrence_at"`
+	}
+	if _, err := trans.Select(&tasks, `
+	SELECT
+		id
+		,start_at
+		,rule_type
+		,last_done_id
+		,last_done_at
+		,last_done_occurrence_at
+	FROM tasks
+	`); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var lines []struct {
+		TaskID       int               `db:"task_id"`
+		Weekday      *model.Weekday    `db:"weekday"`
+		Day          *model.Day        `db:"day"`
+		MonthDay     *model.MonthDay   `db:"month_day"`
+		DateTime     *time.Time        `db:"date_time"`
+		Date         *model.Date       `db:"rule_date"`
+		PeriodNumber *int              `db:"period_number"`
+		PeriodUnit   *model.PeriodUnit `db:"period_unit"`
+	}
+	if _, err := trans.Select(&lines, `
+	SELECT
+		task_id
+		,weekday
+		,day
+		,month_day
+		,date_time
+		,rule_date
+		,period_number
+		,period_unit
+	FROM task_rule_lines
+	`); err != nil {
+		return errors.WithStack(err)
+	}
+
+	taskMap := make(map[int]*Task, len(tasks))
+	for _, t := range tasks {
+		taskMap[t.ID] = &Task{
+			TaskID:       t.ID,
+			TaskStartAt:  t.StartAt,
+			TaskRuleType: t.RuleType,
+			TaskLastDone: TaskLastDone{
+				LastDoneID:           t.LastDoneID,
+				LastDoneAt:           t.LastDoneAt,
+				LastDoneOccurrenceAt: t.LastDoneOccurrenceAt,
+			},
+			TaskRule: NewTaskRule(t.RuleType),
+		}
+	}
+	for _, l := range lines {
+		taskMap[l.TaskID].TaskRule.add(TaskRuleLine{
+			TaskID:     l.TaskID,
+			Weekday:    l.Weekday,
+			Day:        l.Day,
+			MonthDay:   l.MonthDay,
+			DateTime:   l.DateTime,
+			Date:       l.Date,
+			TaskPeriod: TaskPeriod{PeriodNumber: l.PeriodNumber, PeriodUnit: l.PeriodUnit},
+		})
+	}
+
+	for _, t := range tasks {
+		if _, err := trans.Exec(`UPDATE tasks SET deadline_at = ? WHERE id = ?`, taskMap[t.ID].deadline(), t.ID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// migrateNameLowers : for the rows before tasks.name_lower
+func migrateNameLowers(trans *gorp.Transaction) error {
+	var tasks []struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	if _, err := trans.Select(&tasks, `SELECT id, name FROM tasks`); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, t := range tasks {
+		if _, err := trans.Exec(`UPDATE tasks SET name_lower = ? WHERE id = ?`, strings.ToLower(t.Name), t.ID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
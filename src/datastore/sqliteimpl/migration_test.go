@@ -0,0 +1,117 @@
+package sqliteimpl
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// the schema before the migrations
+var baselineSchema = []string{
+	`CREATE TABLE tasks (
+		id integer not null primary key autoincrement
+		,name varchar(255) not null
+		,start_at datetime not null
+		,rule_type varchar(255) not null
+	)`,
+	`CREATE TABLE done_tasks (
+		id integer not null primary key autoincrement
+		,task_id integer not null
+		,name varchar(255) not null
+		,at datetime not null
+	)`,
+	`CREATE TABLE task_rule_lines (
+		id integer not null primary key autoincrement
+		,task_id integer not null
+		,weekday integer
+		,day integer
+		,month_day varchar(255)
+		,date_time datetime
+		,rule_date varchar(255)
+		,period_number integer
+		,period_unit varchar(255)
+	)`,
+	`INSERT INTO tasks (id, name, start_at, rule_type) VALUES (1, 'daily', '2020-01-01 00:00:00', 'periodic')`,
+	`INSERT INTO task_rule_lines (task_id, period_number, period_unit) VALUES (1, 1, 'day')`,
+	`INSERT INTO done_tasks (id, task_id, name, at) VALUES (1, 1, 'daily', '2020-01-02 00:00:00')`,
+	`INSERT INTO done_tasks (id, task_id, name, at) VALUES (2, 1, 'daily', '2020-01-03 00:00:00')`,
+	`INSERT INTO tasks (id, name, start_at, rule_type) VALUES (2, 'Not Done', '2020-01-01 00:00:00', 'periodic')`,
+	`INSERT INTO task_rule_lines (task_id, period_number, period_unit) VALUES (2, 1, 'week')`,
+}
+
+func TestMigrationsFromBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-migration")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.db")
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for _, sql := range baselineSchema {
+		if _, err := db.Exec(sql); err != nil {
+			db.Close()
+			t.Fatalf("%+v", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	dep, err := Setup(WithDataPath(path))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	tasks := dep.TaskRepository.(*TaskRepository)
+
+	version, err := tasks.Db.SelectInt(`SELECT version FROM schema_version`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if int(version) != migrations.Version() {
+		t.Errorf("schema version should be %d, but: %d", migrations.Version(), version)
+	}
+
+	done, err := tasks.One(1)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	stored := done.TaskData.(*Task)
+	if stored.LastDoneID == nil || *stored.LastDoneID != 2 {
+		t.Errorf("should backfill the last done, but: %v", stored.LastDoneID)
+	}
+	wantDeadline := time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC)
+	if stored.DeadlineAt == nil || !stored.DeadlineAt.Equal(wantDeadline) {
+		t.Errorf("should backfill the deadline %v, but: %v", wantDeadline, stored.DeadlineAt)
+	}
+	if stored.UUID == "" || !stored.UpdatedAt.Equal(stored.TaskStartAt) {
+		t.Errorf("should backfill the uuid and the updated at, but: %q, %v", stored.UUID, stored.UpdatedAt)
+	}
+
+	notDone, err := tasks.One(2)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	stored = notDone.TaskData.(*Task)
+	if stored.LastDoneID != nil {
+		t.Errorf("should not have the last done, but: %v", *stored.LastDoneID)
+	}
+	if stored.NameLower != "not done" {
+		t.Errorf("should backfill the lowered name, but: %q", stored.NameLower)
+	}
+	wantDeadline = time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC)
+	if stored.DeadlineAt == nil || !stored.DeadlineAt.Equal(wantDeadline) {
+		t.Errorf("should backfill the deadline %v, but: %v", wantDeadline, stored.DeadlineAt)
+	}
+
+	backup := filepath.Join(dir, "test.db.v0.bak")
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("should back up before migrating: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package sqliteimpl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+// TaskSearchRepository : full text index of tasks
+// NOTE: requires `sqlite_fts5` build tag, the other features work without it.
+type TaskSearchRepository struct {
+	Db *gorp.DbMap
+
+	enabled bool
+}
+
+// ErrSearchUnavailable : sqlite is built without fts5
+var ErrSearchUnavailable = errors.New("task search is unavailable: counteriad is built without `-tags sqlite_fts5`")
+
+// indexed columns in the order of the fts5 table
+var searchColumns = []string{"name", "notes"}
+
+var createIndexSQL = `CREATE VIRTUAL TABLE task_search USING fts5(
+	task_id UNINDEXED
+	,` + strings.Join(searchColumns, "\n\t,") + `
+)`
+
+const (
+	matchStart = "\x01"
+	matchEnd   = "\x02"
+)
+
+// Setup : create the index from tasks if not exists or the indexed columns were changed
+// NOTE: the index is kept by Refresh and Delete after that.
+// The changes while running without fts5 are not indexed until the index is rebuilt.
+func (repo *TaskSearchRepository) Setup(transactions repository.TransactionFactory) error {
+	enabled, err := repo.Db.SelectInt(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	repo.enabled = enabled == 1
+	if !repo.enabled {
+		return nil
+	}
+
+	return transactions.Do(func(transaction repository.Transaction) error {
+		trans := gorpTransaction(transaction)
+
+		created, err := trans.SelectNullStr(`
+		SELECT sql
+		FROM sqlite_master
+		WHERE type = 'table'
+		AND name = 'task_search'
+		`)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if created.Valid && created.String == createIndexSQL {
+			return nil
+		}
+
+		sqls := []string{createIndexSQL, indexSQL}
+		if created.Valid {
+			sqls = append([]string{`DROP TABLE task_search`}, sqls...)
+		}
+		for _, sql := range sqls {
+			if _, err := trans.Exec(sql); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// trashed tasks are not indexed.
+const indexSQL = `
+	INSERT INTO task_search (task_id, name, notes)
+	SELECT
+		t.id
+		,t.name
+		,t.notes
+	FROM tasks t
+	WHERE t.deleted_at IS NULL
+	`
+
+// Refresh : update the task index
+func (repo *TaskSearchRepository) Refresh(transaction repository.Transaction, taskID int) error {
+	if !repo.enabled {
+		return nil
+	}
+	trans := gorpTransaction(transaction)
+
+	if err := repo.Delete(transaction, taskID); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := trans.Exec(indexSQL+"AND t.id = ?", taskID); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete : remove the task from the index
+func (repo *TaskSearchRepository) Delete(transaction repository.Transaction, taskID int) error {
+	if !repo.enabled {
+		return nil
+	}
+	trans := gorpTransaction(transaction)
+
+	if _, err := trans.Exec("DELETE FROM task_search WHERE task_id = ?", taskID); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// SearchRow :
+type SearchRow struct {
+	TaskID int    `db:"task_id"`
+	Name   string `db:"name"`
+	Notes  string `db:"notes"`
+}
+
+// Search : task ids and highlighted fields ordered by relevance
+func (repo *TaskSearchRepository) Search(words string) ([]SearchRow, error) {
+	if !repo.enabled {
+		return nil, ErrSearchUnavailable
+	}
+
+	match := matchQuery(words)
+	if match == "" {
+		return []SearchRow{}, nil
+	}
+
+	highlights := []string{}
+	for i, column := range searchColumns {
+		highlights = append(highlights, "highlight(task_search, "+strconv.Itoa(i+1)+", :start, :end) AS "+column)
+	}
+	rows := []SearchRow{}
+	if _, err := repo.Db.Select(&rows, `
+	SELECT
+		task_id
+		,`+strings.Join(highlights, "\n\t\t,")+`
+	FROM task_search
+	WHERE task_search MATCH :match
+	ORDER BY rank
+	`, map[string]interface{}{
+		"start": matchStart,
+		"end":   matchEnd,
+		"match": match,
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rows, nil
+}
+
+// Fields : matched fields
+func (row SearchRow) Fields() []model.SearchField {
+	fields := []model.SearchField{}
+	for i, text := range []string{row.Name, row.Notes} {
+		field := parseHighlighted(searchColumns[i], text)
+		if len(field.Matches) == 0 {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func parseHighlighted(name string, highlighted string) model.SearchField {
+	var b strings.Builder
+	matches := []model.TextRange{}
+	for {
+		start := strings.Index(highlighted, matchStart)
+		if start == -1 {
+			break
+		}
+		b.WriteString(highlighted[:start])
+		highlighted = highlighted[start+len(matchStart):]
+
+		end := strings.Index(highlighted, matchEnd)
+		if end == -1 {
+			end = len(highlighted)
+		}
+		matches = append(matches, model.TextRange{Start: b.Len(), End: b.Len() + end})
+		b.WriteString(highlighted[:end])
+		highlighted = strings.TrimPrefix(highlighted[end:], matchEnd)
+	}
+	b.WriteString(highlighted)
+
+	return model.SearchField{
+		Name:    name,
+		Text:    b.String(),
+		Matches: matches,
+	}
+}
+
+// matchQuery : quote each word as a prefix query to escape fts5 syntax
+func matchQuery(words string) string {
+	quoted := []string{}
+	for _, word := range strings.Fields(words) {
+		quoted = append(quoted, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(quoted, " ")
+}
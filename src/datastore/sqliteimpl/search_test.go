@@ -0,0 +1,76 @@
+package sqliteimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/pkg/errors"
+)
+
+func TestTaskSearchRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-search")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dep, err := Setup(WithDataPath(filepath.Join(dir, "test.db")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	tasks := dep.TaskRepository.(*TaskRepository)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := tasks.Temporary(now)
+	task.TaskData.(*Task).TaskName = "searched"
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return tasks.Create(transaction, task, now)
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	hits, err := tasks.SearchTasks("sear")
+	if !tasks.Search.enabled {
+		if errors.Cause(err) != ErrSearchUnavailable {
+			t.Errorf("should be unavailable without fts5, but: %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(hits) != 1 {
+		t.Errorf("should hit the task, but: %v", hits)
+	}
+
+	// NOTE: the index with the removed history column
+	for _, sql := range []string{
+		`DROP TABLE task_search`,
+		`CREATE VIRTUAL TABLE task_search USING fts5(task_id UNINDEXED, name, notes, history)`,
+	} {
+		if _, err := tasks.Db.Exec(sql); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	if err := tasks.Search.Setup(dep.TransactionFactory); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	created, err := tasks.Db.SelectStr(`SELECT sql FROM sqlite_master WHERE name = 'task_search'`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if created != createIndexSQL {
+		t.Errorf("should rebuild the index, but: %s", created)
+	}
+	hits, err = tasks.SearchTasks("sear")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(hits) != 1 {
+		t.Errorf("should hit the task after rebuilding, but: %v", hits)
+	}
+}
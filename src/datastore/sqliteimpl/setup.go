@@ -8,32 +8,101 @@ import (
 
 // Setup : tables, dependencies
 func Setup(opts ...func(*database.Config)) (*domain.Dep, error) {
-	config := &database.Config{}
+	config := &database.Config{BackupCount: database.DefaultBackupCount}
 	for _, opt := range opts {
 		opt(config)
 	}
 
 	tables := database.Tables{
-		{Base: Task{}, Name: "tasks"},
-		{Base: DoneTask{}, Name: "done_tasks"},
+		{
+			Base:          Task{},
+			Name:          "tasks",
+			VersionColumn: "version",
+			Indexes: database.Indexes{
+				{Name: "tasks_uuid", Columns: []string{"uuid"}, Unique: true},
+				{Name: "tasks_name", Columns: []string{"name"}},
+				{Name: "tasks_last_done_at", Columns: []string{"last_done_at"}},
+				{Name: "tasks_deadline_at", Columns: []string{"deadline_at"}},
+			},
+		},
+		{
+			Base: DoneTask{},
+			Name: "done_tasks",
+			Indexes: database.Indexes{
+				{Name: "done_tasks_task_id_at", Columns: []string{"task_id", "at"}},
+				{Name: "done_tasks_uuid", Columns: []string{"uuid"}, Unique: true},
+				{Name: "done_tasks_task_id_occurrence_at", Columns: []string{"task_id", "occurrence_at"}},
+			},
+		},
 		{
 			Base:      TaskRuleLine{},
 			Name:      "task_rule_lines",
 			RawChecks: ruleLineChecks,
+			Indexes: database.Indexes{
+				{Name: "task_rule_lines_task_id", Columns: []string{"task_id"}},
+			},
 		},
+		{
+			Base: TaskEvent{},
+			Name: "task_events",
+			Indexes: database.Indexes{
+				{Name: "task_events_task_id", Columns: []string{"task_id"}},
+			},
+		},
+		{Base: TaskTombstone{}, Name: "task_tombstones"},
+		{Base: SyncIdentity{}, Name: "sync_identity"},
+		{Base: SyncPeer{}, Name: "sync_peers"},
 	}
-	dbmap, err := database.Setup(tables, config)
+	dbmap, err := database.Setup(tables, migrations, config)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	transactions := &TransactionFactory{Db: dbmap}
+
+	search := &TaskSearchRepository{Db: dbmap}
+	if err := search.Setup(transactions); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	events := &EventRepository{Db: dbmap, Actor: currentActor()}
+	if err := events.Setup(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dbPath, err := config.Path()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	backupDir, err := config.BackupDir(dbPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tasks := &TaskRepository{
+		Db:     dbmap,
+		Rules:  &TaskRuleLineRepository{Db: dbmap},
+		Dones:  &DoneTaskRepository{Db: dbmap},
+		Search: search,
+		Events: events,
+	}
+	backups := &BackupRepository{
+		Db:    dbmap,
+		Dir:   backupDir,
+		Count: config.BackupCount,
+	}
+
 	return &domain.Dep{
-		TaskRepository: &TaskRepository{
-			Db:    dbmap,
-			Rules: &TaskRuleLineRepository{Db: dbmap},
-			Dones: &DoneTaskRepository{Db: dbmap},
+		TaskRepository:     tasks,
+		TransactionFactory: transactions,
+		BackupRepository:   backups,
+		EventRepository:    events,
+		SyncRepository: &SyncRepository{
+			Db:      dbmap,
+			Path:    dbPath,
+			Tasks:   tasks,
+			Backups: backups,
 		},
-		TransactionFactory: &TransactionFactory{Db: dbmap},
 	}, nil
 }
 
@@ -111,6 +180,20 @@ var ruleLineChecks = []string{
 	)`,
 }
 
+// WithBackupCount :
+func WithBackupCount(count int) func(*database.Config) {
+	return func(op *database.Config) {
+		op.BackupCount = count
+	}
+}
+
+// WithBackupPath :
+func WithBackupPath(path string) func(*database.Config) {
+	return func(op *database.Config) {
+		op.BackupPath = path
+	}
+}
+
 // WithDataPath :
 func WithDataPath(path string) func(*database.Config) {
 	return func(op *database.Config) {
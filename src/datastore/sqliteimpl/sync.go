@@ -0,0 +1,495 @@
+package sqliteimpl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/lib"
+	"github.com/pkg/errors"
+)
+
+// SyncRepository : impl, merges a copy of the database by the uuids
+type SyncRepository struct {
+	Db      *gorp.DbMap
+	Path    string
+	Tasks   *TaskRepository
+	Backups *BackupRepository
+}
+
+var _ repository.SyncRepository = &SyncRepository{}
+
+// TaskTombstone : the purged task, to purge it in the other copies
+type TaskTombstone struct {
+	UUID     string    `db:"uuid, primarykey, size:36"`
+	PurgedAt time.Time `db:"purged_at, notnull"`
+}
+
+// SyncIdentity : identifies this database file as a peer, a single row
+// NOTE: a copied file has the same identity until the first sync between them.
+type SyncIdentity struct {
+	UUID string `db:"uuid, primarykey, size:36"`
+}
+
+// SyncPeer : the last merge with the other database
+type SyncPeer struct {
+	PeerUUID string    `db:"peer_uuid, primarykey, size:36"`
+	SyncedAt time.Time `db:"synced_at, notnull"`
+}
+
+// Sync : merge in both directions after backing up both
+// The done history is the union. The task fields are the last written ones,
+// and reported as a conflict if both were changed since the last sync.
+// This database is committed first, so syncing again completes a sync failed to commit the other.
+func (repo *SyncRepository) Sync(path string, now time.Time) (*model.SyncResult, error) {
+	same, err := sameFile(repo.Path, path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if same {
+		return nil, errors.Errorf("can not sync with the same database: %s", path)
+	}
+
+	otherDep, err := Setup(
+		WithDataPath(path),
+		WithBackupCount(repo.Backups.Count),
+		WithBackupPath(filepath.Dir(repo.Backups.Dir)),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	other := otherDep.SyncRepository.(*SyncRepository)
+	defer other.Db.Db.Close()
+
+	for _, r := range []*SyncRepository{repo, other} {
+		if _, err := r.Backups.Backup(model.BackupReasonSync); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var result *model.SyncResult
+	var remoteTransaction repository.Transaction
+	rollbackRemote := func() {
+		// NOTE: the rollback error is less important than the sync error
+		if remoteTransaction != nil {
+			_ = remoteTransaction.Rollback()
+			remoteTransaction = nil
+		}
+	}
+	defer rollbackRemote()
+
+	local := &TransactionFactory{Db: repo.Db}
+	remote := &TransactionFactory{Db: other.Db}
+	if err := local.Do(func(localTransaction repository.Transaction) error {
+		// NOTE: the work is retried if this database is busy
+		rollbackRemote()
+		transaction, err := remote.Begin()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		remoteTransaction = transaction
+
+		a, err := repo.load(localTransaction, now)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		b, err := other.load(remoteTransaction, now)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := b.meet(a); err != nil {
+			return errors.WithStack(err)
+		}
+		result = &model.SyncResult{Conflicts: []model.SyncConflict{}}
+		return merge(a, b, now, result)
+	}, func() error {
+		transaction := remoteTransaction
+		remoteTransaction = nil
+		if err := transaction.Commit(); err != nil {
+			return errors.Wrapf(err, "merged only into %s, sync again to update %s", repo.Path, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result, nil
+}
+
+func sameFile(path string, otherPath string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	otherInfo, err := os.Stat(otherPath)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return os.SameFile(info, otherInfo), nil
+}
+
+// syncSide : a database in the sync
+type syncSide struct {
+	repo        *SyncRepository
+	transaction repository.Transaction
+	// the time of the merge
+	now time.Time
+	// the identity of this side
+	self string
+	// the last merge with the other side, nil if never synced
+	syncedAt *time.Time
+	// including the trashed tasks
+	tasks      map[string]*Task
+	tombstones map[string]bool
+}
+
+func (repo *SyncRepository) load(transaction repository.Transaction, now time.Time) (*syncSide, error) {
+	trans := gorpTransaction(transaction)
+
+	tasks := []*Task{}
+	if _, err := trans.Select(&tasks, `SELECT * FROM tasks`); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := repo.Tasks.Rules.Bind(tasks...); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var tombstones []string
+	if _, err := trans.Select(&tombstones, `SELECT uuid FROM task_tombstones`); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	self, err := trans.SelectNullStr(`SELECT uuid FROM sync_identity`)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	side := &syncSide{
+		repo:        repo,
+		transaction: transaction,
+		now:         now,
+		self:        self.String,
+		tasks:       make(map[string]*Task, len(tasks)),
+		tombstones:  make(map[string]bool, len(tombstones)),
+	}
+	for _, t := range tasks {
+		side.tasks[t.UUID] = t
+	}
+	for _, uuid := range tombstones {
+		side.tombstones[uuid] = true
+	}
+	if !self.Valid {
+		if err := side.renewIdentity(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return side, nil
+}
+
+func (side *syncSide) renewIdentity() error {
+	if _, err := side.trans().Exec(`DELETE FROM sync_identity`); err != nil {
+		return errors.WithStack(err)
+	}
+	side.self = lib.NewUUID()
+	if err := side.trans().Insert(&SyncIdentity{UUID: side.self}); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// meet : read the last merge of both sides
+// NOTE: renews the identity of this side if copied from the other.
+func (side *syncSide) meet(other *syncSide) error {
+	if side.self == other.self {
+		if err := side.renewIdentity(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, pair := range [][2]*syncSide{{side, other}, {other, side}} {
+		var peers []SyncPeer
+		if _, err := pair[0].trans().Select(&peers, `SELECT * FROM sync_peers WHERE peer_uuid = ?`, pair[1].self); err != nil {
+			return errors.WithStack(err)
+		}
+		if len(peers) != 0 {
+			pair[0].syncedAt = &peers[0].SyncedAt
+		}
+	}
+	return nil
+}
+
+func (side *syncSide) trans() *gorp.Transaction {
+	return gorpTransaction(side.transaction)
+}
+
+func merge(a *syncSide, b *syncSide, now time.Time, result *model.SyncResult) error {
+	for _, pair := range [][2]*syncSide{{a, b}, {b, a}} {
+		purged, err := pair[1].purge(pair[0].tombstones)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		result.Purged += purged
+	}
+
+	uuids := []string{}
+	for uuid := range a.tasks {
+		uuids = append(uuids, uuid)
+	}
+	for uuid := range b.tasks {
+		if _, ok := a.tasks[uuid]; !ok {
+			uuids = append(uuids, uuid)
+		}
+	}
+	sort.Strings(uuids)
+
+	for _, uuid := range uuids {
+		taskA, okA := a.tasks[uuid]
+		taskB, okB := b.tasks[uuid]
+		switch {
+		case !okB:
+			if err := b.copy(taskA); err != nil {
+				return errors.WithStack(err)
+			}
+			result.Created++
+		case !okA:
+			if err := a.copy(taskB); err != nil {
+				return errors.WithStack(err)
+			}
+			result.Created++
+		default:
+			same, err := sameFields(taskA, taskB)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if same {
+				continue
+			}
+
+			winner, loser, winnerSide, loserSide := taskA, taskB, a, b
+			if taskB.UpdatedAt.After(taskA.UpdatedAt) || (taskB.UpdatedAt.Equal(taskA.UpdatedAt) && taskB.TaskVersion > taskA.TaskVersion) {
+				winner, loser, winnerSide, loserSide = taskB, taskA, b, a
+			}
+			if winnerSide.changedSinceSync(winner) && loserSide.changedSinceSync(loser) {
+				result.Conflicts = append(result.Conflicts, model.SyncConflict{
+					TaskName:  winner.TaskName,
+					LoserName: loser.TaskName,
+					Winner:    winnerSide.repo.Path,
+					Loser:     loserSide.repo.Path,
+					WinnerAt:  winner.UpdatedAt,
+					LoserAt:   loser.UpdatedAt,
+				})
+			}
+			if err := loserSide.overwrite(loser, winner); err != nil {
+				return errors.WithStack(err)
+			}
+			result.Updated++
+		}
+	}
+
+	for _, pair := range [][2]*syncSide{{a, b}, {b, a}} {
+		dones, err := pair[1].copyDones(pair[0])
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		result.Dones += dones
+	}
+
+	for _, pair := range [][2]*syncSide{{a, b}, {b, a}} {
+		if _, err := pair[0].trans().Exec(`INSERT OR REPLACE INTO sync_peers (peer_uuid, synced_at) VALUES (?, ?)`, pair[1].self, now); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// changedSinceSync : since the last merge with the other side
+func (side *syncSide) changedSinceSync(task *Task) bool {
+	return side.syncedAt == nil || task.UpdatedAt.After(*side.syncedAt)
+}
+
+// sameFields : ignoring the ids, the versions and the times of the merge
+func sameFields(a *Task, b *Task) (bool, error) {
+	if a.TaskName != b.TaskName ||
+		a.TaskNotes != b.TaskNotes ||
+		!a.TaskStartAt.Equal(b.TaskStartAt) ||
+		a.TaskRuleType != b.TaskRuleType ||
+		(a.DeletedAt == nil) != (b.DeletedAt == nil) ||
+		(a.DeletedAt != nil && !a.DeletedAt.Equal(*b.DeletedAt)) {
+		return false, nil
+	}
+
+	linesA, err := json.Marshal(a.ruleLines())
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	linesB, err := json.Marshal(b.ruleLines())
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return string(linesA) == string(linesB), nil
+}
+
+// purge : the tasks purged in the other side
+func (side *syncSide) purge(tombstones map[string]bool) (int, error) {
+	count := 0
+	for uuid := range tombstones {
+		if side.tombstones[uuid] {
+			continue
+		}
+
+		task, ok := side.tasks[uuid]
+		if !ok {
+			if err := side.trans().Insert(&TaskTombstone{UUID: uuid, PurgedAt: side.now}); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			side.tombstones[uuid] = true
+			continue
+		}
+
+		if err := side.repo.Tasks.purge(side.transaction, task, side.now); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		delete(side.tasks, uuid)
+		side.tombstones[uuid] = true
+		count++
+	}
+	return count, nil
+}
+
+// copy : the task only in the other side
+func (side *syncSide) copy(task *Task) error {
+	t := *task
+	t.TaskID = 0
+	t.TaskLastDone = TaskLastDone{}
+	t.DeadlineAt = t.deadline()
+	if err := side.trans().Insert(&t); err != nil {
+		return errors.WithStack(err)
+	}
+
+	tasks := side.repo.Tasks
+	if err := tasks.Rules.Create(side.transaction, &t); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := tasks.Search.Refresh(side.transaction, t.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := tasks.Events.Append(side.transaction, model.TaskEventTypeCreate, t.TaskID, nil, t.snapshot(), side.now); err != nil {
+		return errors.WithStack(err)
+	}
+
+	side.tasks[t.UUID] = &t
+	return nil
+}
+
+// overwrite : the fields of the loser by the winner
+func (side *syncSide) overwrite(loser *Task, winner *Task) error {
+	t := *winner
+	t.TaskID = loser.TaskID
+	t.TaskVersion = loser.TaskVersion
+	t.TaskLastDone = loser.TaskLastDone
+	t.DeadlineAt = t.deadline()
+	if _, err := side.trans().Update(&t); err != nil {
+		return convertLockError(err)
+	}
+
+	tasks := side.repo.Tasks
+	if err := tasks.Rules.Update(side.transaction, &t); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := tasks.Search.Refresh(side.transaction, t.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := tasks.Events.Append(side.transaction, model.TaskEventTypeUpdate, t.TaskID, loser.snapshot(), t.snapshot(), side.now); err != nil {
+		return errors.WithStack(err)
+	}
+
+	side.tasks[t.UUID] = &t
+	return nil
+}
+
+type syncDone struct {
+	DoneTask
+	TaskUUID string `db:"task_uuid"`
+}
+
+func (side *syncSide) dones() ([]syncDone, error) {
+	dones := []syncDone{}
+	if _, err := side.trans().Select(&dones, `
+	SELECT
+		d.*
+		,t.uuid AS task_uuid
+	FROM done_tasks d
+	JOIN tasks t ON t.id = d.task_id
+	ORDER BY d.at
+	`); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return dones, nil
+}
+
+// copyDones : the dones only in the other side, recorded as a done event per task
+func (side *syncSide) copyDones(other *syncSide) (int, error) {
+	dones, err := side.dones()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	exists := make(map[string]bool, len(dones))
+	for _, d := range dones {
+		exists[d.UUID] = true
+	}
+
+	otherDones, err := other.dones()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	tasks := side.repo.Tasks
+	count := 0
+	befores := make(map[int]*TaskSnapshot)
+	taskIDs := []int{}
+	for _, d := range otherDones {
+		if exists[d.UUID] {
+			continue
+		}
+		task, ok := side.tasks[d.TaskUUID]
+		if !ok {
+			continue
+		}
+
+		if _, ok := befores[task.TaskID]; !ok {
+			befores[task.TaskID] = task.snapshot()
+			taskIDs = append(taskIDs, task.TaskID)
+		}
+
+		done := d.DoneTask
+		done.DoneTaskID = 0
+		done.TaskID = task.TaskID
+		if err := side.trans().Insert(&done); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		count++
+	}
+
+	for _, taskID := range taskIDs {
+		if _, err := side.trans().Exec(`UPDATE tasks SET version = version + 1 WHERE id = ?`, taskID); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if err := tasks.Dones.RefreshLast(side.transaction, taskID); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if err := tasks.Search.Refresh(side.transaction, taskID); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		after, err := tasks.stored(side.trans(), taskID)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if err := tasks.Events.Append(side.transaction, model.TaskEventTypeDone, taskID, befores[taskID], after.snapshot(), side.now); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,140 @@
+package sqliteimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/datastore/sqliteimpl/database"
+	"github.com/notomo/counteria.nvim/src/domain"
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+func setupClosed(t *testing.T, path string) {
+	t.Helper()
+	dep, err := Setup(WithDataPath(path), WithBackupPath(filepath.Join(filepath.Dir(path), "backups")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	// NOTE: the other database should be closed to sync
+	if err := dep.SyncRepository.(*SyncRepository).Db.Db.Close(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+func renameTask(t *testing.T, dep *domain.Dep, id int, name string, now time.Time) {
+	t.Helper()
+	task, err := dep.TaskRepository.One(id)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	task.TaskData.(*Task).TaskName = name
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return dep.TaskRepository.Update(transaction, task, now)
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+func TestSyncRepositoryPerPeer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-sync")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dep, err := Setup(WithDataPath(filepath.Join(dir, "local.db")), WithBackupPath(filepath.Join(dir, "backups")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	pathA := filepath.Join(dir, "a.db")
+	pathB := filepath.Join(dir, "b.db")
+	setupClosed(t, pathA)
+	setupClosed(t, pathB)
+
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	task := dep.TaskRepository.Temporary(day(1))
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		return dep.TaskRepository.Create(transaction, task, day(1))
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if _, err := dep.SyncRepository.Sync(pathA, day(2)); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	renameTask(t, dep, task.ID(), "local", day(3))
+	// NOTE: not to hide the local change from a.db
+	if _, err := dep.SyncRepository.Sync(pathB, day(4)); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	depA, err := Setup(WithDataPath(pathA), WithBackupPath(filepath.Join(dir, "backups")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	renameTask(t, depA, 1, "a", day(5))
+	if err := depA.SyncRepository.(*SyncRepository).Db.Db.Close(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	result, err := dep.SyncRepository.Sync(pathA, day(6))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Errorf("should report the conflict with a.db, but: %v", result.Conflicts)
+	}
+
+	result, err = dep.SyncRepository.Sync(pathA, day(7))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(result.Conflicts) != 0 || result.Updated != 0 {
+		t.Errorf("should be synced, but: %+v", result)
+	}
+}
+
+func TestSyncRepositoryCopiedIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-sync")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "local.db")
+	dep, err := Setup(WithDataPath(path), WithBackupPath(filepath.Join(dir, "backups")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	pathA := filepath.Join(dir, "a.db")
+	setupClosed(t, pathA)
+	if _, err := dep.SyncRepository.Sync(pathA, time.Now()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	copied := filepath.Join(dir, "copied.db")
+	if err := database.VacuumInto(dep.SyncRepository.(*SyncRepository).Db, copied); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := dep.SyncRepository.Sync(copied, time.Now()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	repo := dep.SyncRepository.(*SyncRepository)
+	self, err := repo.Db.SelectStr(`SELECT uuid FROM sync_identity`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	count, err := repo.Db.SelectInt(`SELECT COUNT(*) FROM sync_peers WHERE peer_uuid != ?`, self)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if count != 2 {
+		t.Errorf("should have the copied one as another peer, but: %d peers", count)
+	}
+}
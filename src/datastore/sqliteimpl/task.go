@@ -2,13 +2,14 @@ package sqliteimpl
 
 import (
 	"database/sql"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-gorp/gorp"
 	"github.com/notomo/counteria.nvim/src/domain"
 	"github.com/notomo/counteria.nvim/src/domain/model"
 	"github.com/notomo/counteria.nvim/src/domain/repository"
+	"github.com/notomo/counteria.nvim/src/lib"
 	"github.com/pkg/errors"
 )
 
@@ -16,193 +17,522 @@ import (
 type TaskRepository struct {
 	Db *gorp.DbMap
 
-	Rules *TaskRuleLineRepository
-	Dones *DoneTaskRepository
+	Rules  *TaskRuleLineRepository
+	Dones  *DoneTaskRepository
+	Search *TaskSearchRepository
+	Events *EventRepository
 }
 
 var _ repository.TaskRepository = &TaskRepository{}
 
-// TaskSummary :
-type TaskSummary struct {
-	TaskID       int                `db:"id"`
-	TaskName     string             `db:"name"`
-	StartAt      time.Time          `db:"start_at"`
-	TaskRuleType model.TaskRuleType `db:"rule_type" check:"taskRuleType"`
-
-	LastDoneID *int       `db:"done_id"`
-	LastDoneAt *time.Time `db:"at"`
-}
-
 // List :
 func (repo *TaskRepository) List(option repository.ListOption, now time.Time) ([]model.Task, error) {
-	sql := `
-	SELECT
-		t.*
-		,done.id AS done_id
-		,done.at
-	FROM tasks t
-	LEFT JOIN done_tasks done ON t.id = done.task_id
-		AND NOT EXISTS (
-			SELECT 1
-			FROM done_tasks d
-			WHERE t.id = d.task_id
-			AND done.at < d.at
-		)
-	` + convertListOption(option)
-
-	summaries := []TaskSummary{}
-	if _, err := repo.Db.Select(&summaries, sql); err != nil {
+	sql, args, sliced := convertListOption(option)
+	tasks, err := repo.list(sql, args)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if sliced {
+		return tasks, nil
+	}
 
-	tasks := make([]model.Task, len(summaries))
-	ts := make([]*Task, len(summaries))
-	for i, t := range summaries {
-		task := &Task{
-			TaskID:       t.TaskID,
-			TaskName:     t.TaskName,
-			TaskStartAt:  t.StartAt,
-			TaskRuleType: t.TaskRuleType,
-		}
-		if t.LastDoneID != nil {
-			task.LastDoneTask = &DoneTask{
-				DoneTaskID: *t.LastDoneID,
-				TaskID:     task.TaskID,
-				TaskName:   task.TaskName,
-				DoneAt:     *t.LastDoneAt,
+	if option.Filter.HasState() {
+		filtered := []model.Task{}
+		for _, task := range tasks {
+			if option.Filter.MatchState(task, now) {
+				filtered = append(filtered, task)
 			}
 		}
-		tasks[i] = model.Task{TaskData: task}
-		ts[i] = task
+		tasks = filtered
+	}
+
+	start, end := option.Range(len(tasks))
+	return tasks[start:end], nil
+}
+
+// Count : ignoring limit and offset
+func (repo *TaskRepository) Count(option repository.ListOption, now time.Time) (int, error) {
+	if option.Filter.HasState() {
+		option.Limit = 0
+		option.Offset = 0
+		tasks, err := repo.List(option, now)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		return len(tasks), nil
+	}
+
+	where, args := convertFilter(option.Filter)
+	count, err := repo.Db.SelectInt(`
+	SELECT COUNT(*)
+	FROM tasks t
+	`+where, args)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int(count), nil
+}
+
+func (repo *TaskRepository) list(sqlSuffix string, args map[string]interface{}) ([]model.Task, error) {
+	ts := []*Task{}
+	if _, err := repo.Db.Select(&ts, `
+	SELECT t.*
+	FROM tasks t
+	`+sqlSuffix, args); err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	if err := repo.Rules.Bind(ts...); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if option.Sort.By == repository.SortByTaskRemains {
-		sort.Slice(tasks, func(i, j int) bool {
-			latestI := tasks[i].Deadline(now).Latest()
-			if latestI == nil {
-				return false
-			}
-			latestJ := tasks[j].Deadline(now).Latest()
-			if latestJ == nil {
-				return false
-			}
-			return latestI.Unix() < latestJ.Unix()
+	tasks := make([]model.Task, len(ts))
+	for i, t := range ts {
+		tasks[i] = model.Task{TaskData: t}
+	}
+	return tasks, nil
+}
+
+// Occurrences : without loading the whole done history
+func (repo *TaskRepository) Occurrences(from time.Time, to time.Time) (model.Occurrences, error) {
+	tasks, err := repo.list("WHERE t.deleted_at IS NULL AND t.start_at < :to", map[string]interface{}{"to": to})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dones, err := repo.Dones.ListForOccurrences(from, to)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	doneMap := make(map[int][]model.DoneTask)
+	for _, d := range dones {
+		d := d
+		doneMap[d.TaskID] = append(doneMap[d.TaskID], model.DoneTask{DoneTaskData: &d})
+	}
+
+	occurrences := model.Occurrences{}
+	for _, task := range tasks {
+		occurrences = append(occurrences, task.Occurrences(from, to, doneMap[task.ID()])...)
+	}
+	occurrences.Sort()
+
+	return occurrences, nil
+}
+
+// SearchTasks : ordered by relevance
+func (repo *TaskRepository) SearchTasks(words string) ([]model.SearchHit, error) {
+	rows, err := repo.Search.Search(words)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(rows) == 0 {
+		return []model.SearchHit{}, nil
+	}
+
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.TaskID
+	}
+	tasks, err := repo.list("WHERE t.id IN (:ids)", map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	taskMap := make(map[int]model.Task)
+	for _, task := range tasks {
+		taskMap[task.ID()] = task
+	}
+
+	hits := []model.SearchHit{}
+	for _, row := range rows {
+		task, ok := taskMap[row.TaskID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, model.SearchHit{
+			Task:   task,
+			Fields: row.Fields(),
 		})
 	}
+	return hits, nil
+}
 
-	return tasks, nil
+// History : ordered by done at
+func (repo *TaskRepository) History(taskID int) ([]model.DoneTask, error) {
+	dones, err := repo.Dones.List(taskID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	history := make([]model.DoneTask, len(dones))
+	for i := range dones {
+		history[i] = model.DoneTask{DoneTaskData: &dones[i]}
+	}
+	return history, nil
+}
+
+type historyRow struct {
+	DoneTask
+	PreviousID           *int       `db:"previous_id"`
+	PreviousName         *string    `db:"previous_name"`
+	PreviousAt           *time.Time `db:"previous_at"`
+	PreviousOccurrenceAt *time.Time `db:"previous_occurrence_at"`
+}
+
+func (row historyRow) previous() *model.DoneTask {
+	if row.PreviousID == nil {
+		return nil
+	}
+	return &model.DoneTask{
+		DoneTaskData: &DoneTask{
+			DoneTaskID:       *row.PreviousID,
+			TaskID:           row.TaskID,
+			TaskName:         *row.PreviousName,
+			DoneAt:           *row.PreviousAt,
+			DoneOccurrenceAt: row.PreviousOccurrenceAt,
+		},
+	}
+}
+
+// ListHistory : the dones joined with the tasks and the previous dones
+func (repo *TaskRepository) ListHistory(filter repository.HistoryFilter) (model.HistoryEntries, error) {
+	taskWhere, doneWhere, args := convertHistoryFilter(filter)
+
+	rows := []historyRow{}
+	if _, err := repo.Db.Select(&rows, `
+	SELECT
+		d.*
+		,p.id AS previous_id
+		,p.name AS previous_name
+		,p.at AS previous_at
+		,p.occurrence_at AS previous_occurrence_at
+	FROM done_tasks d
+	JOIN tasks t ON t.id = d.task_id
+	LEFT JOIN done_tasks p ON p.id = (
+		SELECT id
+		FROM done_tasks
+		WHERE task_id = d.task_id
+		AND (at < d.at OR (at = d.at AND id < d.id))
+		ORDER BY at DESC, id DESC
+		LIMIT 1
+	)
+	`+taskWhere+doneWhere+`
+	ORDER BY d.at, d.id
+	`, args); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tasks, err := repo.list(taskWhere, args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	taskMap := make(map[int]model.Task, len(tasks))
+	for _, task := range tasks {
+		taskMap[task.ID()] = task
+	}
+
+	entries := make(model.HistoryEntries, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		entries[i] = model.HistoryEntry{
+			Task:     taskMap[row.TaskID],
+			Done:     model.DoneTask{DoneTaskData: &row.DoneTask},
+			Previous: row.previous(),
+		}
+	}
+	return entries, nil
 }
 
 // Create :
-func (repo *TaskRepository) Create(transaction repository.Transaction, task *model.Task) error {
-	trans := transaction.(*gorp.Transaction)
+func (repo *TaskRepository) Create(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans := gorpTransaction(transaction)
 
 	t := readTask(task)
+	t.UUID = lib.NewUUID()
+	t.UpdatedAt = now
+	t.DeadlineAt = t.deadline()
 	if err := trans.Insert(t); err != nil {
 		return errors.WithStack(err)
 	}
 	task.TaskData = t
 
-	if err := repo.Rules.Create(trans, t); err != nil {
+	if err := repo.Rules.Create(transaction, t); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Search.Refresh(transaction, t.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Events.Append(transaction, model.TaskEventTypeCreate, t.TaskID, nil, t.snapshot(), now); err != nil {
 		return errors.WithStack(err)
 	}
 
 	return nil
 }
 
-// Update :
-func (repo *TaskRepository) Update(transaction repository.Transaction, task *model.Task) error {
-	trans := transaction.(*gorp.Transaction)
+// Update : fails if the task was changed after the version
+func (repo *TaskRepository) Update(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans := gorpTransaction(transaction)
 
-	t := readTask(task)
-	if _, err := trans.Update(t); err != nil {
+	t, stored, err := repo.readStored(trans, task)
+	if err != nil {
 		return errors.WithStack(err)
 	}
+	t.UpdatedAt = now
+	if _, err := trans.Update(t); err != nil {
+		return convertLockError(err)
+	}
 	task.TaskData = t
 
-	if err := repo.Rules.Update(trans, t); err != nil {
+	if err := repo.Rules.Update(transaction, t); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Search.Refresh(transaction, t.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Events.Append(transaction, model.TaskEventTypeUpdate, t.TaskID, stored.snapshot(), t.snapshot(), now); err != nil {
 		return errors.WithStack(err)
 	}
 
 	return nil
 }
 
-// Done :
-func (repo *TaskRepository) Done(transaction repository.Transaction, task *model.Task, now time.Time) error {
-	if err := repo.Dones.Create(transaction, task, now); err != nil {
+// Done : and increment the version
+func (repo *TaskRepository) Done(transaction repository.Transaction, task *model.Task, now time.Time, occurrenceAt *time.Time) error {
+	trans := gorpTransaction(transaction)
+
+	stored, err := repo.stored(trans, task.ID())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Dones.Create(transaction, task, now, occurrenceAt); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := trans.Exec(`UPDATE tasks SET version = version + 1 WHERE id = ?`, task.ID()); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := repo.Search.Refresh(transaction, task.ID()); err != nil {
+		return errors.WithStack(err)
+	}
+
+	after := *stored
+	after.TaskVersion++
+	// NOTE: same as RefreshLast, the later or the newer done is the last
+	if after.LastDoneAt == nil || !now.Before(*after.LastDoneAt) {
+		after.LastDoneAt = &now
+	}
+	if err := repo.Events.Append(transaction, model.TaskEventTypeDone, task.ID(), stored.snapshot(), after.snapshot(), now); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-// Delete :
-func (repo *TaskRepository) Delete(transaction repository.Transaction, task *model.Task) error {
-	trans := transaction.(*gorp.Transaction)
-	taskID := task.ID()
+// ImportHistory : not to conflict with the other clients by the version
+func (repo *TaskRepository) ImportHistory(transaction repository.Transaction, task *model.Task, dones []model.DoneTask) (int, error) {
+	trans := gorpTransaction(transaction)
+
+	stored := []DoneTask{}
+	if _, err := trans.Select(&stored, `SELECT * FROM done_tasks WHERE task_id = ?`, task.ID()); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	exists := make(map[int64]bool, len(stored))
+	for _, d := range stored {
+		exists[d.DoneAt.UnixNano()] = true
+	}
+
+	count := 0
+	for _, d := range dones {
+		if exists[d.At().UnixNano()] {
+			continue
+		}
+		exists[d.At().UnixNano()] = true
+		if err := trans.Insert(&DoneTask{
+			UUID:             lib.NewUUID(),
+			TaskID:           task.ID(),
+			TaskName:         d.Name(),
+			DoneAt:           d.At(),
+			DoneOccurrenceAt: d.OccurrenceAt(),
+		}); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	if err := repo.Dones.RefreshLast(transaction, task.ID()); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return count, nil
+}
+
+// Delete : move to the trash
+func (repo *TaskRepository) Delete(transaction repository.Transaction, task *model.Task, now time.Time) error {
+	trans := gorpTransaction(transaction)
 
-	if err := repo.Dones.Delete(trans, taskID); err != nil {
+	t, stored, err := repo.readStored(trans, task)
+	if err != nil {
 		return errors.WithStack(err)
 	}
+	t.DeletedAt = &now
+	t.UpdatedAt = now
+	if _, err := trans.Update(t); err != nil {
+		return convertLockError(err)
+	}
 
-	if err := repo.Rules.Delete(trans, taskID); err != nil {
+	if err := repo.Search.Delete(transaction, t.TaskID); err != nil {
 		return errors.WithStack(err)
 	}
 
-	if _, err := trans.Delete(task.TaskData); err != nil {
+	if err := repo.Events.Append(transaction, model.TaskEventTypeDelete, t.TaskID, stored.snapshot(), t.snapshot(), now); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
+// Trash : ordered by deleted at desc
+func (repo *TaskRepository) Trash() ([]model.TrashedTask, error) {
+	tasks, err := repo.list("WHERE t.deleted_at IS NOT NULL ORDER BY t.deleted_at DESC", map[string]interface{}{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	trashed := make([]model.TrashedTask, len(tasks))
+	for i, task := range tasks {
+		trashed[i] = model.TrashedTask{
+			Task:      task,
+			DeletedAt: *task.TaskData.(*Task).DeletedAt,
+		}
+	}
+	return trashed, nil
+}
+
+// trashed : the stored trashed task
+func (repo *TaskRepository) trashed(transaction repository.Transaction, taskID int) (*Task, error) {
+	stored, err := repo.stored(gorpTransaction(transaction), taskID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if stored.DeletedAt == nil {
+		return nil, domain.ErrNotFound
+	}
+	return stored, nil
+}
+
+// Restore : recorded as an update event
+func (repo *TaskRepository) Restore(transaction repository.Transaction, taskID int, now time.Time) error {
+	trans := gorpTransaction(transaction)
+
+	stored, err := repo.trashed(transaction, taskID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := trans.Exec(`UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ?`, now, taskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Search.Refresh(transaction, taskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	after := *stored
+	after.TaskVersion++
+	after.DeletedAt = nil
+	if err := repo.Events.Append(transaction, model.TaskEventTypeUpdate, taskID, stored.snapshot(), after.snapshot(), now); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Purge :
+func (repo *TaskRepository) Purge(transaction repository.Transaction, taskID int, now time.Time) error {
+	stored, err := repo.trashed(transaction, taskID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return repo.purge(transaction, stored, now)
+}
+
+// purge : delete the task with the done history and leave the tombstone for sync
+func (repo *TaskRepository) purge(transaction repository.Transaction, task *Task, now time.Time) error {
+	trans := gorpTransaction(transaction)
+
+	if err := repo.Dones.Delete(transaction, task.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Rules.Delete(transaction, task.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Search.Delete(transaction, task.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := trans.Exec(`DELETE FROM tasks WHERE id = ?`, task.TaskID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := trans.Insert(&TaskTombstone{UUID: task.UUID, PurgedAt: now}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := repo.Events.Append(transaction, model.TaskEventTypeDelete, task.TaskID, task.snapshot(), nil, now); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// PurgeBefore :
+func (repo *TaskRepository) PurgeBefore(transaction repository.Transaction, at time.Time, now time.Time) (int, error) {
+	trans := gorpTransaction(transaction)
+
+	var ids []int
+	if _, err := trans.Select(&ids, `SELECT id FROM tasks WHERE deleted_at < ?`, at); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	for _, id := range ids {
+		if err := repo.Purge(transaction, id, now); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	return len(ids), nil
+}
+
+func convertLockError(err error) error {
+	lockErr, ok := errors.Cause(err).(gorp.OptimisticLockError)
+	if !ok {
+		return errors.WithStack(err)
+	}
+	if !lockErr.RowExists {
+		return domain.ErrNotFound
+	}
+	return errors.Wrap(domain.ErrConflict, lockErr.Error())
+}
+
 // One :
 func (repo *TaskRepository) One(id int) (*model.Task, error) {
-	var t TaskSummary
-	err := repo.Db.SelectOne(&t, ` 
-	SELECT
-		t.*
-		,done.id AS done_id
-		,done.at
+	var task Task
+	if err := repo.Db.SelectOne(&task, `
+	SELECT t.*
 	FROM tasks t
-	LEFT JOIN done_tasks done ON t.id = done.task_id
-		AND NOT EXISTS (
-			SELECT 1
-			FROM done_tasks d
-			WHERE t.id = d.task_id
-			AND done.at < d.at
-		)
 	WHERE t.id = ?
-	`, id)
-	if err != nil {
+	AND t.deleted_at IS NULL
+	`, id); err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, errors.WithStack(err)
 	}
 
-	task := &Task{
-		TaskID:       t.TaskID,
-		TaskName:     t.TaskName,
-		TaskStartAt:  t.StartAt,
-		TaskRuleType: t.TaskRuleType,
-	}
-	if t.LastDoneID != nil {
-		task.LastDoneTask = &DoneTask{
-			DoneTaskID: *t.LastDoneID,
-			TaskID:     task.TaskID,
-			TaskName:   task.TaskName,
-			DoneAt:     *t.LastDoneAt,
-		}
-	}
-
-	if err := repo.Rules.Bind(task); err != nil {
+	if err := repo.Rules.Bind(&task); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return &model.Task{TaskData: task}, nil
+	return &model.Task{TaskData: &task}, nil
 }
 
 // Temporary :
@@ -218,17 +548,50 @@ func (repo *TaskRepository) Temporary(now time.Time) *model.Task {
 
 // Task :
 type Task struct {
-	TaskID       int                `db:"id, primarykey, autoincrement"`
-	TaskName     string             `db:"name, notnull" check:"notEmpty"`
+	TaskID   int    `db:"id, primarykey, autoincrement"`
+	TaskName string `db:"name, notnull" check:"notEmpty"`
+	// lowered by Go to filter by the name ignoring case as the other datastores
+	// NOTE: LIKE in sqlite ignores only the ASCII case.
+	NameLower    string             `db:"name_lower, notnull"`
+	TaskNotes    string             `db:"notes, notnull"`
+	TaskVersion  int                `db:"version, notnull"`
 	TaskStartAt  time.Time          `db:"start_at, notnull"`
 	TaskRuleType model.TaskRuleType `db:"rule_type, notnull" check:"taskRuleType"`
+	DeletedAt    *time.Time         `db:"deleted_at"`
+	// identifies the task across the database copies
+	UUID string `db:"uuid, notnull, size:36"`
+	// the last change of the fields, used to merge the copies
+	UpdatedAt time.Time `db:"updated_at, notnull"`
+	// NOTE: not used, the last merge is stored per peer in sync_peers.
+	SyncedAt *time.Time `db:"synced_at"`
+	TaskLastDone
+	// the latest deadline, cached to sort by the remains in sql
+	DeadlineAt *time.Time `db:"deadline_at"`
+
+	TaskRule *TaskRule `db:"-"`
+}
 
-	LastDoneTask *DoneTask `db:"-"`
-	TaskRule     *TaskRule `db:"-"`
+// TaskLastDone : the latest done, denormalised to list without joining the dones
+type TaskLastDone struct {
+	LastDoneID           *int       `db:"last_done_id"`
+	LastDoneAt           *time.Time `db:"last_done_at"`
+	LastDoneOccurrenceAt *time.Time `db:"last_done_occurrence_at"`
 }
 
 var _ model.TaskData = &Task{}
 
+// PreInsert : gorp hook
+func (task *Task) PreInsert(gorp.SqlExecutor) error {
+	task.NameLower = strings.ToLower(task.TaskName)
+	return nil
+}
+
+// PreUpdate : gorp hook
+func (task *Task) PreUpdate(gorp.SqlExecutor) error {
+	task.NameLower = strings.ToLower(task.TaskName)
+	return nil
+}
+
 // ID :
 func (task *Task) ID() int {
 	return task.TaskID
@@ -239,6 +602,16 @@ func (task *Task) Name() string {
 	return task.TaskName
 }
 
+// Notes :
+func (task *Task) Notes() string {
+	return task.TaskNotes
+}
+
+// Version :
+func (task *Task) Version() int {
+	return task.TaskVersion
+}
+
 // Rule :
 func (task *Task) Rule() *model.TaskRule {
 	return &model.TaskRule{TaskRuleData: task.TaskRule}
@@ -251,19 +624,119 @@ func (task *Task) StartAt() time.Time {
 
 // LastDone :
 func (task *Task) LastDone() *model.DoneTask {
-	if task.LastDoneTask == nil {
+	if task.LastDoneID == nil {
 		return nil
 	}
 	return &model.DoneTask{
-		DoneTaskData: task.LastDoneTask,
+		DoneTaskData: &DoneTask{
+			DoneTaskID:       *task.LastDoneID,
+			TaskID:           task.TaskID,
+			TaskName:         task.TaskName,
+			DoneAt:           *task.LastDoneAt,
+			DoneOccurrenceAt: task.LastDoneOccurrenceAt,
+		},
 	}
 }
 
+// stored : the task in the transaction with the rule
+func (repo *TaskRepository) stored(trans *gorp.Transaction, taskID int) (*Task, error) {
+	var stored Task
+	if err := trans.SelectOne(&stored, `SELECT * FROM tasks WHERE id = ?`, taskID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	lines := []TaskRuleLine{}
+	if _, err := trans.Select(&lines, `
+	SELECT *
+	FROM task_rule_lines
+	WHERE task_id = ?
+	ORDER BY id
+	`, taskID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	stored.TaskRule = NewTaskRule(stored.TaskRuleType)
+	for _, line := range lines {
+		stored.TaskRule.add(line)
+	}
+	return &stored, nil
+}
+
+// readStored : with the stored columns which are not changed by the update, and the deadline by them
+// NOTE: returns the stored task also to record the event.
+func (repo *TaskRepository) readStored(trans *gorp.Transaction, task *model.Task) (*Task, *Task, error) {
+	stored, err := repo.stored(trans, task.ID())
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	t := readTask(task)
+	t.UUID = stored.UUID
+	t.TaskLastDone = stored.TaskLastDone
+	t.DeadlineAt = t.deadline()
+	return t, stored, nil
+}
+
+// deadline : the latest deadline by the rule and the last done, in UTC to compare as text
+// NOTE: the latest deadline does not depend on the current time.
+func (task *Task) deadline() *time.Time {
+	latest := model.Deadline{
+		Rule:     task.Rule(),
+		StartAt:  task.StartAt(),
+		LastDone: task.LastDone(),
+	}.Latest()
+	if latest == nil {
+		return nil
+	}
+	utc := latest.UTC()
+	return &utc
+}
+
+// refreshDeadlines : update the cached deadlines of the tasks by the stored rules and last dones
+func refreshDeadlines(trans *gorp.Transaction, where string, args map[string]interface{}) error {
+	ts := []*Task{}
+	if _, err := trans.Select(&ts, `
+	SELECT t.*
+	FROM tasks t
+	`+where, args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines := []TaskRuleLine{}
+	if _, err := trans.Select(&lines, `
+	SELECT l.*
+	FROM task_rule_lines l
+	JOIN tasks t ON t.id = l.task_id
+	`+where, args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	taskMap := make(map[int]*Task, len(ts))
+	for _, t := range ts {
+		t.TaskRule = NewTaskRule(t.TaskRuleType)
+		taskMap[t.TaskID] = t
+	}
+	for _, line := range lines {
+		taskMap[line.TaskID].TaskRule.add(line)
+	}
+
+	for _, t := range ts {
+		if _, err := trans.Exec(`UPDATE tasks SET deadline_at = ? WHERE id = ?`, t.deadline(), t.TaskID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
 func readTask(task *model.Task) *Task {
 	rule := task.Rule()
 	return &Task{
 		TaskID:       task.ID(),
 		TaskName:     task.Name(),
+		TaskNotes:    task.Notes(),
+		TaskVersion:  task.Version(),
 		TaskStartAt:  task.StartAt(),
 		TaskRuleType: rule.Type(),
 		TaskRule:     readTaskRule(rule),
@@ -0,0 +1,225 @@
+package sqliteimpl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+const (
+	benchTaskCount      = 10000
+	benchDonesPerTask   = 100
+	benchFrameBudget    = 16 * time.Millisecond
+	benchDatabaseEnvKey = "COUNTERIA_BENCH_DB"
+)
+
+var (
+	benchOnce sync.Once
+	benchRepo *TaskRepository
+	benchDir  string
+	benchErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchDir != "" {
+		os.RemoveAll(benchDir)
+	}
+	os.Exit(code)
+}
+
+// benchTasks : 10k tasks with 1M dones, created once per process
+// NOTE: the file is kept if COUNTERIA_BENCH_DB is set, to skip creating it again.
+func benchTasks(b *testing.B) *TaskRepository {
+	b.Helper()
+	benchOnce.Do(func() {
+		path := os.Getenv(benchDatabaseEnvKey)
+		if path == "" {
+			benchDir, benchErr = ioutil.TempDir("", "counteria-bench")
+			if benchErr != nil {
+				return
+			}
+			path = filepath.Join(benchDir, "bench.db")
+		}
+		benchRepo, benchErr = setupBenchTasks(path)
+	})
+	if benchErr != nil {
+		b.Fatalf("%+v", benchErr)
+	}
+	return benchRepo
+}
+
+func setupBenchTasks(path string) (*TaskRepository, error) {
+	dep, err := Setup(WithDataPath(path))
+	if err != nil {
+		return nil, err
+	}
+	repo := dep.TaskRepository.(*TaskRepository)
+
+	count, err := repo.Db.SelectInt(`SELECT COUNT(*) FROM tasks`)
+	if err != nil {
+		return nil, err
+	}
+	if count != 0 {
+		return repo, nil
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	template := repo.Temporary(start)
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		if err := repo.Create(transaction, template, start); err != nil {
+			return err
+		}
+
+		trans := gorpTransaction(transaction)
+		args := map[string]interface{}{
+			"template": template.ID(),
+			"tasks":    benchTaskCount,
+			"dones":    benchDonesPerTask,
+		}
+		for _, sql := range []string{`
+		WITH RECURSIVE n(i) AS (SELECT 2 UNION ALL SELECT i + 1 FROM n WHERE i < :tasks)
+		INSERT INTO tasks (name, notes, version, start_at, rule_type, uuid, updated_at)
+		SELECT
+			printf('task %05d', n.i)
+			,t.notes
+			,t.version
+			,t.start_at
+			,t.rule_type
+			,printf('task-%d', n.i)
+			,t.updated_at
+		FROM n, tasks t
+		WHERE t.id = :template
+		`, `
+		INSERT INTO task_rule_lines (task_id, weekday, day, month_day, date_time, rule_date, period_number, period_unit)
+		SELECT
+			t.id
+			,l.weekday
+			,l.day
+			,l.month_day
+			,l.date_time
+			,l.rule_date
+			,l.period_number
+			,l.period_unit
+		FROM tasks t, task_rule_lines l
+		WHERE l.task_id = :template
+		AND t.id != :template
+		`, `
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < :dones)
+		INSERT INTO done_tasks (task_id, name, at, uuid)
+		SELECT
+			t.id
+			,t.name
+			,datetime('2020-01-01', printf('+%d hours', n.i * 24 + t.id % 24))
+			,printf('done-%d-%d', t.id, n.i)
+		FROM tasks t, n
+		`} {
+			if _, err := trans.Exec(sql, args); err != nil {
+				return err
+			}
+		}
+		if err := migrateLastDones(trans); err != nil {
+			return err
+		}
+		return refreshDeadlines(trans, "", map[string]interface{}{})
+	}); err != nil {
+		return nil, err
+	}
+
+	// NOTE: reading through the large WAL file is slower than the steady state.
+	if _, err := repo.Db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// benchWithin : fails if an operation takes longer than the budget on average, no limit if zero
+func benchWithin(b *testing.B, budget time.Duration, op func() error) {
+	b.Helper()
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if err := op(); err != nil {
+			b.Fatalf("%+v", err)
+		}
+	}
+	perOp := time.Since(start) / time.Duration(b.N)
+	b.StopTimer()
+
+	if budget != 0 && perOp > budget {
+		b.Errorf("%s per operation exceeds the budget %s", perOp, budget)
+	}
+}
+
+func benchListOption(by repository.SortBy) repository.ListOption {
+	return repository.ListOption{
+		Sort: repository.Sort{
+			By:    by,
+			Order: repository.SortOrderAsc,
+		},
+		Limit: 100,
+	}
+}
+
+func BenchmarkTaskRepositoryList(b *testing.B) {
+	repo := benchTasks(b)
+	now := time.Now()
+	for _, c := range []struct {
+		by     repository.SortBy
+		budget time.Duration
+	}{
+		{by: repository.SortByTaskName, budget: benchFrameBudget},
+		{by: repository.SortByTaskDoneAt, budget: benchFrameBudget},
+		{by: repository.SortByTaskRemains, budget: benchFrameBudget},
+	} {
+		option := benchListOption(c.by)
+		b.Run(string(c.by), func(b *testing.B) {
+			benchWithin(b, c.budget, func() error {
+				tasks, err := repo.List(option, now)
+				if err != nil {
+					return err
+				}
+				if len(tasks) != option.Limit {
+					return fmt.Errorf("unexpected task count: %d", len(tasks))
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func BenchmarkTaskRepositoryCount(b *testing.B) {
+	repo := benchTasks(b)
+	now := time.Now()
+	option := benchListOption(repository.SortByTaskName)
+	benchWithin(b, benchFrameBudget, func() error {
+		count, err := repo.Count(option, now)
+		if err != nil {
+			return err
+		}
+		if count != benchTaskCount {
+			return fmt.Errorf("unexpected task count: %d", count)
+		}
+		return nil
+	})
+}
+
+func BenchmarkTaskRepositoryOne(b *testing.B) {
+	repo := benchTasks(b)
+	benchWithin(b, benchFrameBudget, func() error {
+		task, err := repo.One(benchTaskCount / 2)
+		if err != nil {
+			return err
+		}
+		if task.LastDone() == nil {
+			return fmt.Errorf("no last done: %d", task.ID())
+		}
+		return nil
+	})
+}
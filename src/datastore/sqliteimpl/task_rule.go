@@ -16,7 +16,7 @@ type TaskRuleLineRepository struct {
 
 // Create :
 func (repo *TaskRuleLineRepository) Create(transaction repository.Transaction, task *Task) error {
-	trans := transaction.(*gorp.Transaction)
+	trans := gorpTransaction(transaction)
 
 	lines := task.ruleLines()
 	ls := make([]interface{}, len(lines))
@@ -33,7 +33,7 @@ func (repo *TaskRuleLineRepository) Create(transaction repository.Transaction, t
 
 // Delete :
 func (repo *TaskRuleLineRepository) Delete(transaction repository.Transaction, taskID int) error {
-	trans := transaction.(*gorp.Transaction)
+	trans := gorpTransaction(transaction)
 
 	lines, err := repo.List(taskID)
 	if err != nil {
@@ -75,32 +75,39 @@ func (repo *TaskRuleLineRepository) Bind(tasks ...*Task) error {
 	return nil
 }
 
+// the number of the ids in a statement
+// NOTE: sqlite limits the number of the variables in a statement.
+const ruleLineIDChunk = 500
+
 // List :
 func (repo *TaskRuleLineRepository) List(taskIDs ...int) ([]TaskRuleLine, error) {
 	lines := []TaskRuleLine{}
-	if len(taskIDs) == 0 {
-		return lines, nil
-	}
+	for start := 0; start < len(taskIDs); start += ruleLineIDChunk {
+		end := start + ruleLineIDChunk
+		if end > len(taskIDs) {
+			end = len(taskIDs)
+		}
 
-	if _, err := repo.Db.Select(&lines, `
-	SELECT *
-	FROM task_rule_lines
-	WHERE task_id IN (:ids)
-	`, map[string]interface{}{"ids": taskIDs}); err != nil {
-		return nil, errors.WithStack(err)
+		chunk := []TaskRuleLine{}
+		if _, err := repo.Db.Select(&chunk, `
+		SELECT *
+		FROM task_rule_lines
+		WHERE task_id IN (:ids)
+		`, map[string]interface{}{"ids": taskIDs[start:end]}); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		lines = append(lines, chunk...)
 	}
 	return lines, nil
 }
 
 // Update : delete and insert
 func (repo *TaskRuleLineRepository) Update(transaction repository.Transaction, task *Task) error {
-	trans := transaction.(*gorp.Transaction)
-
-	if err := repo.Delete(trans, task.ID()); err != nil {
+	if err := repo.Delete(transaction, task.ID()); err != nil {
 		return errors.WithStack(err)
 	}
 
-	if err := repo.Create(trans, task); err != nil {
+	if err := repo.Create(transaction, task); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -214,15 +221,15 @@ func (rule *TaskRule) Periods() model.Periods {
 	return rule.RulePeriods
 }
 
-// TaskRuleLine :
+// TaskRuleLine : json for the event snapshots
 type TaskRuleLine struct {
-	ID       int             `db:"id, primarykey, autoincrement"`
-	TaskID   int             `db:"task_id, notnull" foreign:"tasks(id)"`
-	Weekday  *model.Weekday  `db:"weekday" check:"weekday"`
-	Day      *model.Day      `db:"day" check:"day"`
-	MonthDay *model.MonthDay `db:"month_day"`
-	DateTime *time.Time      `db:"date_time"`
-	Date     *model.Date     `db:"rule_date"` // avoid using `date`
+	ID       int             `db:"id, primarykey, autoincrement" json:"-"`
+	TaskID   int             `db:"task_id, notnull" foreign:"tasks(id)" json:"-"`
+	Weekday  *model.Weekday  `db:"weekday" check:"weekday" json:"weekday,omitempty"`
+	Day      *model.Day      `db:"day" check:"day" json:"day,omitempty"`
+	MonthDay *model.MonthDay `db:"month_day" json:"monthDay,omitempty"`
+	DateTime *time.Time      `db:"date_time" json:"dateTime,omitempty"`
+	Date     *model.Date     `db:"rule_date" json:"date,omitempty"` // avoid using `date`
 	TaskPeriod
 }
 
@@ -230,8 +237,8 @@ var _ model.PeriodData = &TaskPeriod{}
 
 // TaskPeriod :
 type TaskPeriod struct {
-	PeriodNumber *int              `db:"period_number" check:"natural"`
-	PeriodUnit   *model.PeriodUnit `db:"period_unit" check:"periodUnit"`
+	PeriodNumber *int              `db:"period_number" check:"natural" json:"periodNumber,omitempty"`
+	PeriodUnit   *model.PeriodUnit `db:"period_unit" check:"periodUnit" json:"periodUnit,omitempty"`
 }
 
 // Number :
@@ -0,0 +1,134 @@
+package sqliteimpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/repository"
+)
+
+func TestTaskRepositoryOccurrences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-occurrence")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dep, err := Setup(WithDataPath(filepath.Join(dir, "test.db")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	day := func(d int, hour int) time.Time {
+		return time.Date(2020, 1, d, hour, 0, 0, 0, time.UTC)
+	}
+	linkedAt := day(11, 12)
+	task := dep.TaskRepository.Temporary(day(1, 0))
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		if err := dep.TaskRepository.Create(transaction, task, day(1, 0)); err != nil {
+			return err
+		}
+		for _, done := range []struct {
+			at           time.Time
+			occurrenceAt *time.Time
+		}{
+			{at: day(3, 12)},
+			// NOTE: restarts the daily occurrences at 12:00
+			{at: day(9, 12)},
+			{at: day(9, 13), occurrenceAt: &linkedAt},
+			// NOTE: after the window
+			{at: day(13, 12)},
+		} {
+			if err := dep.TaskRepository.Done(transaction, task, done.at, done.occurrenceAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	occurrences, err := dep.TaskRepository.Occurrences(day(10, 0), day(13, 0))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	want := []struct {
+		at   time.Time
+		done bool
+	}{
+		{at: day(10, 12)},
+		{at: day(11, 12), done: true},
+		{at: day(12, 12)},
+	}
+	if len(occurrences) != len(want) {
+		t.Fatalf("should have %d occurrences, but: %v", len(want), occurrences)
+	}
+	for i, o := range occurrences {
+		if !o.At.Equal(want[i].at) || o.Done != want[i].done {
+			t.Errorf("want %v (done: %v), but: %v (done: %v)", want[i].at, want[i].done, o.At, o.Done)
+		}
+	}
+
+}
+
+func TestTaskRepositoryListByName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counteria-list")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dep, err := Setup(WithDataPath(filepath.Join(dir, "test.db")))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := dep.TransactionFactory.Do(func(transaction repository.Transaction) error {
+		for _, name := range []string{"Ärzte", "CAFÉ", "100%"} {
+			task := dep.TaskRepository.Temporary(now)
+			task.TaskData.(*Task).TaskName = name
+			if err := dep.TaskRepository.Create(transaction, task, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	for _, c := range []struct {
+		name string
+		want []string
+	}{
+		{name: "ärz", want: []string{"Ärzte"}},
+		{name: "Café", want: []string{"CAFÉ"}},
+		{name: "0%", want: []string{"100%"}},
+		{name: "_", want: []string{}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			filter := repository.Filter{Name: c.name}
+			tasks, err := dep.TaskRepository.List(repository.ListOption{
+				Sort:   repository.Sort{By: repository.SortByTaskName, Order: repository.SortOrderAsc},
+				Filter: filter,
+			}, now)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			got := []string{}
+			for _, task := range tasks {
+				got = append(got, task.Name())
+				if !filter.Match(task, now) {
+					t.Errorf("should be consistent with Filter.Match: %s", task.Name())
+				}
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package sqliteimpl
 
 import (
+	"time"
+
 	"github.com/go-gorp/gorp"
+	"github.com/mattn/go-sqlite3"
+	"github.com/notomo/counteria.nvim/src/domain"
 	"github.com/notomo/counteria.nvim/src/domain/repository"
 	"github.com/pkg/errors"
 )
@@ -13,11 +17,62 @@ type TransactionFactory struct {
 	Db *gorp.DbMap
 }
 
+// the waits between retries after the busy timeout
+var retryWaits = []time.Duration{
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+}
+
 // Begin :
 func (factory *TransactionFactory) Begin() (repository.Transaction, error) {
 	trans, err := factory.Db.Begin()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, convertBusy(err)
+	}
+	return &Transaction{Transaction: trans}, nil
+}
+
+// Do : retry the whole work while another process holds the write lock
+// NOTE: a busy commit can not be retried alone because the driver rolls back the transaction.
+func (factory *TransactionFactory) Do(work func(repository.Transaction) error, afterCommits ...func() error) error {
+	return repository.RunUnitOfWork(factory.Begin, retryWaits, isBusy, work, afterCommits...)
+}
+
+var _ repository.Transaction = &Transaction{}
+
+// Transaction : impl
+type Transaction struct {
+	*gorp.Transaction
+}
+
+// Commit :
+func (trans *Transaction) Commit() error {
+	if err := trans.Transaction.Commit(); err != nil {
+		return convertBusy(err)
+	}
+	return nil
+}
+
+// gorpTransaction : the only place to unwrap the transaction given to the repositories
+func gorpTransaction(transaction repository.Transaction) *gorp.Transaction {
+	return transaction.(*Transaction).Transaction
+}
+
+func isBusy(err error) bool {
+	cause := errors.Cause(err)
+	if cause == domain.ErrBusy {
+		return true
+	}
+	sqliteErr, ok := cause.(sqlite3.Error)
+	if !ok {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+func convertBusy(err error) error {
+	if isBusy(err) {
+		return errors.Wrap(domain.ErrBusy, err.Error())
 	}
-	return trans, nil
+	return errors.WithStack(err)
 }
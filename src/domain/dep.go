@@ -6,4 +6,7 @@ import "github.com/notomo/counteria.nvim/src/domain/repository"
 type Dep struct {
 	TaskRepository     repository.TaskRepository
 	TransactionFactory repository.TransactionFactory
+	BackupRepository   repository.BackupRepository
+	EventRepository    repository.EventRepository
+	SyncRepository     repository.SyncRepository
 }
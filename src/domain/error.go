@@ -5,4 +5,10 @@ import "fmt"
 var (
 	// ErrNotFound :
 	ErrNotFound = fmt.Errorf("not found")
+	// ErrBusy : the datastore is locked by another process
+	ErrBusy = fmt.Errorf("busy")
+	// ErrConflict : the data was changed since it was read
+	ErrConflict = fmt.Errorf("conflict")
+	// ErrNotSupported : the operation is not supported by the datastore
+	ErrNotSupported = fmt.Errorf("not supported")
 )
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// AgendaSection : group of tasks by deadline
+type AgendaSection string
+
+var (
+	// AgendaSectionOverdue :
+	AgendaSectionOverdue = AgendaSection("Overdue")
+	// AgendaSectionToday :
+	AgendaSectionToday = AgendaSection("Today")
+	// AgendaSectionTomorrow :
+	AgendaSectionTomorrow = AgendaSection("Tomorrow")
+	// AgendaSectionThisWeek : until saturday
+	AgendaSectionThisWeek = AgendaSection("This week")
+	// AgendaSectionLater : including tasks without deadline
+	AgendaSectionLater = AgendaSection("Later")
+	// AgendaSectionDoneToday :
+	AgendaSectionDoneToday = AgendaSection("Done today")
+)
+
+func (section AgendaSection) String() string {
+	return string(section)
+}
+
+// AgendaSections : in display order
+func AgendaSections() []AgendaSection {
+	return []AgendaSection{
+		AgendaSectionOverdue,
+		AgendaSectionToday,
+		AgendaSectionTomorrow,
+		AgendaSectionThisWeek,
+		AgendaSectionLater,
+		AgendaSectionDoneToday,
+	}
+}
+
+// AgendaSection : returns false if the task is already finished
+func (task *Task) AgendaSection(now time.Time) (AgendaSection, bool) {
+	today := endOfDay(now)
+
+	doneAt := task.DoneAt()
+	if doneAt != nil && endOfDay(*doneAt).Equal(today) {
+		return AgendaSectionDoneToday, true
+	}
+
+	deadline := task.Deadline(now)
+	if deadline.Done {
+		return "", false
+	}
+
+	latest := deadline.Latest()
+	switch {
+	case latest == nil:
+		return AgendaSectionLater, true
+	case latest.Before(now):
+		return AgendaSectionOverdue, true
+	case !latest.After(today):
+		return AgendaSectionToday, true
+	case !latest.After(today.AddDate(0, 0, 1)):
+		return AgendaSectionTomorrow, true
+	case !latest.After(today.AddDate(0, 0, int(time.Saturday-now.Weekday()))):
+		return AgendaSectionThisWeek, true
+	}
+	return AgendaSectionLater, true
+}
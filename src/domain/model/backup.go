@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Backup : a snapshot of the datastore
+type Backup struct {
+	// unix milliseconds at the backup, the next one if taken in the same millisecond
+	ID     int
+	At     time.Time
+	Reason BackupReason
+	Size   int64
+}
+
+// BackupReason : why the backup was made
+type BackupReason string
+
+var (
+	// BackupReasonStart : on daemon start
+	BackupReasonStart = BackupReason("start")
+	// BackupReasonPurge : before purging a trashed task
+	BackupReasonPurge = BackupReason("purge")
+	// BackupReasonImport : before replacing all tasks
+	BackupReasonImport = BackupReason("import")
+	// BackupReasonRestore : before restoring another backup
+	BackupReasonRestore = BackupReason("restore")
+	// BackupReasonSync : before merging another database
+	BackupReasonSync = BackupReason("sync")
+)
+
+func (reason BackupReason) String() string {
+	return string(reason)
+}
@@ -16,6 +16,11 @@ func (date Date) Time() time.Time {
 	return t
 }
 
+// End : the end of the date in local time
+func (date Date) End() time.Time {
+	return endOfDay(date.Time())
+}
+
 // Contains :
 func (date Date) Contains(at time.Time) bool {
 	t := date.Time()
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// TaskEvent : a change of a task
+type TaskEvent struct {
+	ID       int
+	TaskID   int
+	TaskName string
+	Type     TaskEventType
+	At       time.Time
+	// user@host of the changer
+	Actor string
+	// the JSON snapshots of the task, empty if not exists
+	Before string
+	After  string
+}
+
+// TaskEventType : how the task was changed
+type TaskEventType string
+
+var (
+	// TaskEventTypeCreate :
+	TaskEventTypeCreate = TaskEventType("create")
+	// TaskEventTypeUpdate :
+	TaskEventTypeUpdate = TaskEventType("update")
+	// TaskEventTypeDelete :
+	TaskEventTypeDelete = TaskEventType("delete")
+	// TaskEventTypeDone :
+	TaskEventTypeDone = TaskEventType("done")
+)
+
+// TaskEventTypes :
+func TaskEventTypes() []TaskEventType {
+	return []TaskEventType{
+		TaskEventTypeCreate,
+		TaskEventTypeUpdate,
+		TaskEventTypeDelete,
+		TaskEventTypeDone,
+	}
+}
+
+func (typ TaskEventType) String() string {
+	return string(typ)
+}
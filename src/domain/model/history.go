@@ -0,0 +1,21 @@
+package model
+
+import "sort"
+
+// HistoryEntry : a done with the task
+type HistoryEntry struct {
+	Task Task
+	Done DoneTask
+	// the previous done of the task, nil if the first one
+	Previous *DoneTask
+}
+
+// HistoryEntries :
+type HistoryEntries []HistoryEntry
+
+// Sort : by done time
+func (entries HistoryEntries) Sort() {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Done.At().Before(entries[j].Done.At())
+	})
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Occurrence : a due time of the task
+type Occurrence struct {
+	Task Task
+	At   time.Time
+	Done bool
+}
+
+// Occurrences :
+type Occurrences []Occurrence
+
+// Sort : by due time, task id
+func (occurrences Occurrences) Sort() {
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if occurrences[i].At.Equal(occurrences[j].At) {
+			return occurrences[i].Task.ID() < occurrences[j].Task.ID()
+		}
+		return occurrences[i].At.Before(occurrences[j].At)
+	})
+}
+
+// Tasks : unique tasks in order
+func (occurrences Occurrences) Tasks() []Task {
+	tasks := []Task{}
+	exists := make(map[int]bool)
+	for _, o := range occurrences {
+		id := o.Task.ID()
+		if exists[id] {
+			continue
+		}
+		exists[id] = true
+		tasks = append(tasks, o.Task)
+	}
+	return tasks
+}
+
+// OccurrenceIterator : iterate due times in order
+type OccurrenceIterator struct {
+	next func() *time.Time
+}
+
+// Next : returns nil if there is no more occurrence
+func (iter *OccurrenceIterator) Next() *time.Time {
+	return iter.next()
+}
+
+// Between : occurrences in [from, to)
+func (iter *OccurrenceIterator) Between(from time.Time, to time.Time) []time.Time {
+	times := []time.Time{}
+	for {
+		at := iter.Next()
+		if at == nil || !at.Before(to) {
+			return times
+		}
+		if at.Before(from) {
+			continue
+		}
+		times = append(times, *at)
+	}
+}
+
+func newEmptyIterator() *OccurrenceIterator {
+	return &OccurrenceIterator{
+		next: func() *time.Time {
+			return nil
+		},
+	}
+}
+
+// NOTE: times must be sorted
+func newSliceIterator(times []time.Time) *OccurrenceIterator {
+	i := 0
+	return &OccurrenceIterator{
+		next: func() *time.Time {
+			if i >= len(times) {
+				return nil
+			}
+			t := times[i]
+			i++
+			return &t
+		},
+	}
+}
+
+// NOTE: stops if the period does not advance to avoid infinite loop
+func newPeriodIterator(period Period, startAt time.Time) *OccurrenceIterator {
+	return newPeriodIteratorFrom(period, startAt, startAt)
+}
+
+// newPeriodIteratorFrom : skips the periods before from
+func newPeriodIteratorFrom(period Period, startAt time.Time, from time.Time) *OccurrenceIterator {
+	n := period.countBefore(startAt, from)
+	last := period.NthTime(startAt, n)
+	return &OccurrenceIterator{
+		next: func() *time.Time {
+			n++
+			t := period.NthTime(startAt, n)
+			if !t.After(last) {
+				return nil
+			}
+			last = t
+			return &t
+		},
+	}
+}
+
+// NOTE: matches must return true at least one day in a month to avoid infinite loop
+func newDayIterator(startAt time.Time, matches func(time.Time) bool) *OccurrenceIterator {
+	day := endOfDay(startAt)
+	return &OccurrenceIterator{
+		next: func() *time.Time {
+			for {
+				t := day
+				day = endOfDay(day.AddDate(0, 0, 1))
+				if t.After(startAt) && matches(t) {
+					return &t
+				}
+			}
+		},
+	}
+}
+
+func endOfDay(at time.Time) time.Time {
+	y, m, d := at.Date()
+	return time.Date(y, m, d, 23, 59, 59, 999999999, time.Local)
+}
+
+func sortTimes(times []time.Time) []time.Time {
+	sorted := make([]time.Time, len(times))
+	copy(sorted, times)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+	return sorted
+}
+
+func timesAfter(times []time.Time, at time.Time) []time.Time {
+	after := []time.Time{}
+	for _, t := range times {
+		if t.After(at) {
+			after = append(after, t)
+		}
+	}
+	return after
+}
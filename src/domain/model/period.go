@@ -15,9 +15,53 @@ type PeriodData interface {
 
 // FromTime : return from + period
 func (period Period) FromTime(from time.Time) time.Time {
+	return period.NthTime(from, 1)
+}
+
+// NthTime : return from + period * n
+// NOTE: not repeating FromTime to avoid drifting at the end of month
+func (period Period) NthTime(from time.Time, n int) time.Time {
+	year, month, day := period.Unit().numbers()
+	number := period.Number() * n
+	return addDate(from, year*number, month*number, day*number)
+}
+
+// countBefore : the number of the periods from startAt before at
+func (period Period) countBefore(startAt time.Time, at time.Time) int {
+	if period.Number() < 1 || !startAt.Before(at) {
+		return 0
+	}
+
 	year, month, day := period.Unit().numbers()
-	number := period.Number()
-	return from.AddDate(year*number, month*number, day*number)
+	var n int
+	if day > 0 {
+		n = int(at.Sub(startAt)/(24*time.Hour)) / (day * period.Number())
+	} else {
+		months := (at.Year()-startAt.Year())*12 + int(at.Month()-startAt.Month())
+		n = months / ((year*12 + month) * period.Number())
+	}
+	// NOTE: the estimate may be off by the daylight saving time or the end of month.
+	for n > 0 && !period.NthTime(startAt, n).Before(at) {
+		n--
+	}
+	for period.NthTime(startAt, n+1).Before(at) {
+		n++
+	}
+	return n
+}
+
+// addDate : time.AddDate clamping the day to the end of the month
+// e.g. Jan 31 + 1 month is Feb 29 (or 28), not normalized to Mar 2.
+func addDate(from time.Time, years int, months int, days int) time.Time {
+	y, m, d := from.Date()
+	hour, min, sec := from.Clock()
+	// NOTE: the day 0 is the last day of the previous month
+	last := time.Date(y+years, m+time.Month(months)+1, 0, 0, 0, 0, 0, from.Location()).Day()
+	if d > last {
+		d = last
+	}
+	t := time.Date(y+years, m+time.Month(months), d, hour, min, sec, from.Nanosecond(), from.Location())
+	return t.AddDate(0, 0, days)
 }
 
 // PeriodUnit :
@@ -88,30 +88,25 @@ func (rule *TaskRule) NextTime(startAt time.Time, lastDone *DoneTask) *time.Time
 		if lastDone == nil {
 			return rule.Periods().NextTime(startAt)
 		}
-		return rule.Periods().NextTime(lastDone.At())
-	case TaskRuleTypeByTimes:
-		if lastDone == nil {
-			return rule.DateTimes().NextTime(startAt)
-		}
-		return nil
-	case TaskRuleTypeInDates:
+		return rule.Periods().NextTime(lastDone.Fulfilled())
+	case TaskRuleTypeByTimes, TaskRuleTypeInDates:
 		if lastDone == nil {
-			return rule.Dates().NextTime(startAt)
+			return rule.Occurrences(startAt).Next()
 		}
-		return nil
+		return rule.Occurrences(rule.FulfilledTime(startAt, lastDone)).Next()
 	case TaskRuleTypeInDaysEveryMonth:
 		if lastDone == nil {
 			return rule.Days().NextTime(startAt)
 		}
 		days := rule.Days()
-		y, m, _ := lastDone.At().Date()
+		y, m, _ := lastDone.Fulfilled().Date()
 		at := time.Date(y, m, int(days[0]), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
 		return days.NextTime(at)
 	case TaskRuleTypeInWeekdays:
 		if lastDone == nil {
 			return rule.Weekdays().NextTime(startAt)
 		}
-		return rule.Weekdays().NextTime(lastDone.At())
+		return rule.Weekdays().NextTime(lastDone.Fulfilled())
 	case TaskRuleTypeNone:
 		return nil
 	}
@@ -124,26 +119,127 @@ func (rule *TaskRule) LastTime(startAt time.Time, lastDone *DoneTask) *time.Time
 	switch typ {
 	case TaskRuleTypePeriodic:
 		return nil
-	case TaskRuleTypeByTimes:
-		return rule.DateTimes().NextTime(startAt)
-	case TaskRuleTypeInDates:
-		return rule.Dates().NextTime(startAt)
+	case TaskRuleTypeByTimes, TaskRuleTypeInDates:
+		iter := rule.Occurrences(startAt)
+		var last *time.Time
+		for at := iter.Next(); at != nil; at = iter.Next() {
+			last = at
+		}
+		return last
 	case TaskRuleTypeInDaysEveryMonth:
 		if lastDone == nil {
 			return rule.Days().NextTime(startAt)
 		}
-		return rule.Days().NextTime(lastDone.At())
+		return rule.Days().NextTime(lastDone.Fulfilled())
 	case TaskRuleTypeInWeekdays:
 		if lastDone == nil {
 			return rule.Weekdays().NextTime(startAt)
 		}
-		return rule.Weekdays().NextTime(lastDone.At())
+		return rule.Weekdays().NextTime(lastDone.Fulfilled())
 	case TaskRuleTypeNone:
 		return nil
 	}
 	panic("unreachable: invalid rule type: " + typ)
 }
 
+// FulfilledTime : the occurrence which the done fulfills
+// NOTE: a done not linked to any occurrence fulfills the first one after the done (or the last one).
+func (rule *TaskRule) FulfilledTime(startAt time.Time, done *DoneTask) time.Time {
+	return rule.FulfilledTimes(startAt, []DoneTask{*done})[0]
+}
+
+// FulfilledTimes : FulfilledTime of each done by iterating the occurrences once
+// NOTE: dones must be ordered by done time.
+func (rule *TaskRule) FulfilledTimes(startAt time.Time, dones []DoneTask) []time.Time {
+	times := make([]time.Time, len(dones))
+	iter := rule.Occurrences(startAt)
+	next := iter.Next()
+	var last *time.Time
+	for i := range dones {
+		done := &dones[i]
+		if at := done.OccurrenceAt(); at != nil {
+			times[i] = *at
+			continue
+		}
+
+		doneAt := done.At()
+		for next != nil && next.Before(doneAt) {
+			last = next
+			next = iter.Next()
+		}
+		switch {
+		case next != nil:
+			times[i] = *next
+		case last != nil:
+			times[i] = *last
+		default:
+			times[i] = doneAt
+		}
+	}
+	return times
+}
+
+// OccurrencesFrom : iterate due times after startAt and not before from
+// NOTE: the periods before from are skipped without iterating them.
+func (rule *TaskRule) OccurrencesFrom(startAt time.Time, from time.Time) *OccurrenceIterator {
+	if !startAt.Before(from) {
+		return rule.Occurrences(startAt)
+	}
+	if rule.Type() == TaskRuleTypePeriodic {
+		periods := rule.Periods()
+		if len(periods) == 0 {
+			return newEmptyIterator()
+		}
+		return newPeriodIteratorFrom(periods[0], startAt, from)
+	}
+	// NOTE: the other rules use startAt only as the lower bound.
+	return rule.Occurrences(from.Add(-time.Nanosecond))
+}
+
+// Occurrences : iterate due times after startAt
+func (rule *TaskRule) Occurrences(startAt time.Time) *OccurrenceIterator {
+	typ := rule.Type()
+	switch typ {
+	case TaskRuleTypePeriodic:
+		periods := rule.Periods()
+		if len(periods) == 0 {
+			return newEmptyIterator()
+		}
+		return newPeriodIterator(periods[0], startAt)
+	case TaskRuleTypeByTimes:
+		times := sortTimes(rule.DateTimes())
+		return newSliceIterator(timesAfter(times, startAt))
+	case TaskRuleTypeInDates:
+		times := []time.Time{}
+		for _, date := range rule.Dates() {
+			times = append(times, date.End())
+		}
+		return newSliceIterator(timesAfter(sortTimes(times), startAt))
+	case TaskRuleTypeInDaysEveryMonth:
+		days := rule.Days()
+		if len(days) == 0 {
+			return newEmptyIterator()
+		}
+		return newDayIterator(startAt, func(at time.Time) bool {
+			for _, day := range days {
+				if day.Contains(at) {
+					return true
+				}
+			}
+			return false
+		})
+	case TaskRuleTypeInWeekdays:
+		weekdays := rule.Weekdays()
+		if len(weekdays) == 0 {
+			return newEmptyIterator()
+		}
+		return newDayIterator(startAt, weekdays.Contains)
+	case TaskRuleTypeNone:
+		return newEmptyIterator()
+	}
+	panic("unreachable: invalid rule type: " + typ)
+}
+
 // Validate :
 func (rule *TaskRule) Validate() error {
 	typ := rule.Type()
@@ -152,6 +248,11 @@ func (rule *TaskRule) Validate() error {
 		if len(rule.Periods()) == 0 {
 			return NewErrValidation(ErrValidationRule, "empty periods")
 		}
+		for _, period := range rule.Periods() {
+			if period.Number() < 1 {
+				return NewErrValidation(ErrValidationRule, "period number must be positive")
+			}
+		}
 		return nil
 	case TaskRuleTypeByTimes:
 		if len(rule.DateTimes()) == 0 {
@@ -0,0 +1,256 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testPeriod struct {
+	number int
+	unit   PeriodUnit
+}
+
+func (period testPeriod) Number() int {
+	return period.number
+}
+
+func (period testPeriod) Unit() PeriodUnit {
+	return period.unit
+}
+
+type testRule struct {
+	typ       TaskRuleType
+	weekdays  Weekdays
+	dates     Dates
+	days      Days
+	dateTimes DateTimes
+	periods   []testPeriod
+}
+
+func (rule testRule) Type() TaskRuleType {
+	return rule.typ
+}
+
+func (rule testRule) Weekdays() Weekdays {
+	return rule.weekdays
+}
+
+func (rule testRule) Dates() Dates {
+	return rule.dates
+}
+
+func (rule testRule) MonthDays() MonthDays {
+	return nil
+}
+
+func (rule testRule) Days() Days {
+	return rule.days
+}
+
+func (rule testRule) DateTimes() DateTimes {
+	return rule.dateTimes
+}
+
+func (rule testRule) Periods() Periods {
+	periods := Periods{}
+	for _, p := range rule.periods {
+		periods = append(periods, Period{PeriodData: p})
+	}
+	return periods
+}
+
+func periodicRule(number int, unit PeriodUnit) *TaskRule {
+	return &TaskRule{TaskRuleData: testRule{
+		typ:     TaskRuleTypePeriodic,
+		periods: []testPeriod{{number: number, unit: unit}},
+	}}
+}
+
+func TestTaskRuleValidate(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		rule  *TaskRule
+		valid bool
+	}{
+		{name: "one day", rule: periodicRule(1, PeriodUnitDay), valid: true},
+		{name: "zero day", rule: periodicRule(0, PeriodUnitDay)},
+		{name: "negative month", rule: periodicRule(-1, PeriodUnitMonth)},
+		{name: "empty periods", rule: &TaskRule{TaskRuleData: testRule{typ: TaskRuleTypePeriodic}}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.rule.Validate()
+			if c.valid {
+				if err != nil {
+					t.Errorf("unexpected error: %+v", err)
+				}
+				return
+			}
+			validationErr, ok := err.(ErrValidation)
+			if !ok || validationErr.Err != ErrValidationRule {
+				t.Errorf("should be a rule validation error, but: %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskRuleOccurrencesNotAdvancing(t *testing.T) {
+	startAt := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		name string
+		rule *TaskRule
+	}{
+		{name: "zero day", rule: periodicRule(0, PeriodUnitDay)},
+		{name: "negative week", rule: periodicRule(-1, PeriodUnitWeek)},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if at := c.rule.Occurrences(startAt).Next(); at != nil {
+				t.Errorf("should stop, but: %s", at)
+			}
+
+			got := c.rule.Occurrences(startAt).Between(startAt, startAt.AddDate(1, 0, 0))
+			if len(got) != 0 {
+				t.Errorf("should be empty, but: %v", got)
+			}
+		})
+	}
+}
+
+func TestTaskRuleFulfilledTimes(t *testing.T) {
+	startAt := localTime(2020, 1, 1, 0)
+	times := []time.Time{localTime(2020, 1, 2, 9), localTime(2020, 1, 3, 9), localTime(2020, 1, 4, 9)}
+	for _, c := range []struct {
+		name  string
+		rule  *TaskRule
+		dones []*DoneTask
+		want  []time.Time
+	}{
+		{
+			name: "the first occurrence after each done",
+			rule: byTimesRule(times...),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 1, 12)),
+				doneAt(localTime(2020, 1, 2, 9)),
+				doneAt(localTime(2020, 1, 2, 12)),
+			},
+			want: []time.Time{times[0], times[0], times[1]},
+		},
+		{
+			name: "the linked occurrence",
+			rule: byTimesRule(times...),
+			dones: []*DoneTask{
+				doneFor(localTime(2020, 1, 3, 12), times[0]),
+				doneAt(localTime(2020, 1, 3, 13)),
+			},
+			want: []time.Time{times[0], times[2]},
+		},
+		{
+			name: "the last occurrence after all",
+			rule: byTimesRule(times...),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 5, 0)),
+			},
+			want: []time.Time{times[2]},
+		},
+		{
+			name: "the done time without occurrences",
+			rule: byTimesRule(),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 5, 0)),
+			},
+			want: []time.Time{localTime(2020, 1, 5, 0)},
+		},
+		{
+			name: "monthly from the end of month",
+			rule: periodicRule(1, PeriodUnitMonth),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 2, 10, 0)),
+				doneAt(localTime(2020, 3, 10, 0)),
+			},
+			want: []time.Time{localTime(2020, 2, 29, 0), localTime(2020, 3, 31, 0)},
+		},
+		{
+			name: "weekdays",
+			rule: weekdaysRule(Weekday(time.Monday), Weekday(time.Thursday)),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 6, 10)),
+				doneAt(localTime(2020, 1, 7, 10)),
+			},
+			want: []time.Time{localEndOfDay(2020, 1, 6), localEndOfDay(2020, 1, 9)},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			ruleStartAt := startAt
+			if c.rule.Type() == TaskRuleTypePeriodic {
+				ruleStartAt = localTime(2020, 1, 31, 0)
+			}
+			dones := make([]DoneTask, len(c.dones))
+			for i, d := range c.dones {
+				dones[i] = *d
+			}
+
+			got := c.rule.FulfilledTimes(ruleStartAt, dones)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+			for i, d := range c.dones {
+				if at := c.rule.FulfilledTime(ruleStartAt, d); !at.Equal(c.want[i]) {
+					t.Errorf("FulfilledTime should be %v, but: %v", c.want[i], at)
+				}
+			}
+		})
+	}
+}
+
+func TestPeriodNthTime(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		period testPeriod
+		from   time.Time
+		n      int
+		want   time.Time
+	}{
+		{
+			name:   "month clamped to the end of leap February",
+			period: testPeriod{number: 1, unit: PeriodUnitMonth},
+			from:   localTime(2020, 1, 31, 9),
+			n:      1,
+			want:   localTime(2020, 2, 29, 9),
+		},
+		{
+			name:   "month clamped to the end of February",
+			period: testPeriod{number: 1, unit: PeriodUnitMonth},
+			from:   localTime(2021, 1, 31, 9),
+			n:      1,
+			want:   localTime(2021, 2, 28, 9),
+		},
+		{
+			name:   "nth month not drifting",
+			period: testPeriod{number: 1, unit: PeriodUnitMonth},
+			from:   localTime(2020, 1, 31, 9),
+			n:      3,
+			want:   localTime(2020, 4, 30, 9),
+		},
+		{
+			name:   "year from the leap day",
+			period: testPeriod{number: 1, unit: PeriodUnitYear},
+			from:   localTime(2020, 2, 29, 9),
+			n:      1,
+			want:   localTime(2021, 2, 28, 9),
+		},
+		{
+			name:   "days over the end of month",
+			period: testPeriod{number: 2, unit: PeriodUnitDay},
+			from:   localTime(2020, 1, 31, 9),
+			n:      1,
+			want:   localTime(2020, 2, 2, 9),
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := Period{PeriodData: c.period}.NthTime(c.from, c.n)
+			if !got.Equal(c.want) {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+// SearchHit : a task matched by search words
+type SearchHit struct {
+	Task   Task
+	Fields []SearchField
+}
+
+// SearchField : a matched text of the task
+type SearchField struct {
+	Name    string
+	Text    string
+	Matches []TextRange
+}
+
+// TextRange : byte range [Start, End) in the text
+type TextRange struct {
+	Start int
+	End   int
+}
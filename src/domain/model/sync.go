@@ -0,0 +1,45 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// SyncResult : the changes by merging two databases in both directions
+type SyncResult struct {
+	// copied tasks
+	Created int
+	// tasks overwritten by the newer side
+	Updated int
+	// tasks purged on the other side
+	Purged int
+	// copied done records
+	Dones     int
+	Conflicts []SyncConflict
+}
+
+func (result SyncResult) String() string {
+	return fmt.Sprintf("created: %d, updated: %d, purged: %d, dones: %d, conflicts: %d", result.Created, result.Updated, result.Purged, result.Dones, len(result.Conflicts))
+}
+
+// SyncConflict : a task changed on both sides since the last sync, the last writer won
+type SyncConflict struct {
+	// the name of the winner
+	TaskName string
+	// the name of the overwritten one
+	LoserName string
+	// the database names
+	Winner string
+	Loser  string
+	// the modification times
+	WinnerAt time.Time
+	LoserAt  time.Time
+}
+
+func (conflict SyncConflict) String() string {
+	return fmt.Sprintf("%s: %s (%s) won over %s (%s: %s)",
+		conflict.TaskName,
+		conflict.Winner, conflict.WinnerAt.Format("2006-01-02 15:04:05"),
+		conflict.Loser, conflict.LoserAt.Format("2006-01-02 15:04:05"), conflict.LoserName,
+	)
+}
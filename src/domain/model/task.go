@@ -2,6 +2,7 @@ package model
 
 import (
 	"math"
+	"sort"
 	"time"
 )
 
@@ -14,11 +15,29 @@ type Task struct {
 type TaskData interface {
 	ID() int
 	Name() string
+	Notes() string
+	// incremented on every change to detect stale writes
+	Version() int
 	StartAt() time.Time
 	LastDone() *DoneTask
 	Rule() *TaskRule
 }
 
+// SourcedTaskData : the task data in one of the aggregated datastores
+type SourcedTaskData interface {
+	TaskData
+	// the name of the datastore having the task
+	Source() string
+}
+
+// Source : the datastore name having the task, empty if not aggregated
+func (task *Task) Source() string {
+	if sourced, ok := task.TaskData.(SourcedTaskData); ok {
+		return sourced.Source()
+	}
+	return ""
+}
+
 // Validate :
 func (task *Task) Validate() error {
 	return task.Rule().Validate()
@@ -40,20 +59,87 @@ func (task *Task) Done(now time.Time) bool {
 	switch typ {
 	case TaskRuleTypePeriodic:
 		return false
-	case TaskRuleTypeByTimes:
-		return task.LastDone() != nil
+	case TaskRuleTypeByTimes, TaskRuleTypeInDates:
+		lastDone := task.LastDone()
+		return lastDone != nil && task.Rule().NextTime(task.StartAt(), lastDone) == nil
 	case TaskRuleTypeInDaysEveryMonth:
-		return task.LastDone() != nil && task.Rule().Days().Contains(task.LastDone().At(), now)
-	case TaskRuleTypeInDates:
-		return task.LastDone() != nil
+		return task.LastDone() != nil && task.Rule().Days().Contains(task.LastDone().Fulfilled(), now)
 	case TaskRuleTypeInWeekdays:
-		return task.LastDone() != nil && task.Rule().Weekdays().Contains(task.LastDone().At())
+		// NOTE: done only in the day to count missed weekdays
+		lastDone := task.LastDone()
+		return lastDone != nil && task.Rule().Weekdays().Contains(lastDone.At()) && endOfDay(lastDone.At()).Equal(endOfDay(now))
 	case TaskRuleTypeNone:
 		return task.LastDone() != nil
 	}
 	panic("unreachable: invalid rule type: " + typ)
 }
 
+// Occurrences : due times in [from, to) with done states by the done history
+// NOTE: a done not linked to any occurrence fulfills the first one after the done.
+// periodic rule is restarted from the done.
+// The history before the window is not needed except the last not linked done,
+// the occurrences are iterated from it or the window start.
+func (task *Task) Occurrences(from time.Time, to time.Time, dones []DoneTask) Occurrences {
+	linked := make(map[int64]bool)
+	unlinked := []DoneTask{}
+	for _, d := range dones {
+		if at := d.OccurrenceAt(); at != nil {
+			linked[at.UnixNano()] = true
+			continue
+		}
+		unlinked = append(unlinked, d)
+	}
+	sort.Slice(unlinked, func(i, j int) bool {
+		return unlinked[i].At().Before(unlinked[j].At())
+	})
+
+	rule := task.Rule()
+	startAt := task.StartAt()
+	var seed *DoneTask
+	i := 0
+	for ; i < len(unlinked) && unlinked[i].At().Before(from); i++ {
+		seed = &unlinked[i]
+	}
+	var seedFulfilled *time.Time
+	if seed != nil {
+		if rule.Type() == TaskRuleTypePeriodic {
+			startAt = seed.At()
+		} else {
+			seedFulfilled = rule.OccurrencesFrom(startAt, seed.At()).Next()
+		}
+	}
+
+	iter := rule.OccurrencesFrom(startAt, from)
+	occurrences := Occurrences{}
+	var prev *time.Time
+	for {
+		at := iter.Next()
+		if at == nil || !at.Before(to) {
+			return occurrences
+		}
+
+		var lastDone *DoneTask
+		for ; i < len(unlinked) && !unlinked[i].At().After(*at); i++ {
+			if prev == nil || unlinked[i].At().After(*prev) {
+				lastDone = &unlinked[i]
+			}
+		}
+		if lastDone != nil && rule.Type() == TaskRuleTypePeriodic {
+			iter = rule.Occurrences(lastDone.At())
+		}
+		prev = at
+
+		if at.Before(from) {
+			continue
+		}
+		occurrences = append(occurrences, Occurrence{
+			Task: *task,
+			At:   *at,
+			Done: lastDone != nil || linked[at.UnixNano()] || (seedFulfilled != nil && seedFulfilled.Equal(*at)),
+		})
+	}
+}
+
 // IsActive :
 func (task *Task) IsActive(now time.Time) bool {
 	rule := task.Rule()
@@ -109,6 +195,70 @@ func (deadline Deadline) Latest() *time.Time {
 	return deadline.Rule.LastTime(deadline.StartAt, deadline.LastDone)
 }
 
+// Missed : due times passed without done since the last done
+func (deadline Deadline) Missed() []time.Time {
+	if deadline.Done {
+		return nil
+	}
+
+	rule := deadline.Rule
+	typ := rule.Type()
+	switch typ {
+	case TaskRuleTypePeriodic:
+		base := deadline.StartAt
+		if deadline.LastDone != nil {
+			base = deadline.LastDone.Fulfilled()
+		}
+		return rule.Occurrences(base).Between(base, deadline.Now)
+	case TaskRuleTypeByTimes, TaskRuleTypeInDates, TaskRuleTypeInDaysEveryMonth, TaskRuleTypeInWeekdays:
+		occurrences := rule.Occurrences(deadline.StartAt).Between(deadline.StartAt, deadline.Now)
+		if deadline.LastDone == nil {
+			return occurrences
+		}
+		fulfilled := rule.FulfilledTime(deadline.StartAt, deadline.LastDone)
+		for i, o := range occurrences {
+			if o.After(fulfilled) {
+				return occurrences[i:]
+			}
+		}
+		return nil
+	case TaskRuleTypeNone:
+		return nil
+	}
+	panic("unreachable: invalid rule type: " + typ)
+}
+
+// DoneMode : how to mark missed occurrences as done
+type DoneMode int
+
+const (
+	// DoneModeCatchUp : catch up once and reschedule from now
+	DoneModeCatchUp = DoneMode(iota)
+	// DoneModeOldest : mark only the oldest missed occurrence
+	DoneModeOldest
+)
+
+// Fulfill : the occurrence which a new done fulfills
+// NOTE: nil means the done is not linked to any occurrence and the task is rescheduled from the done.
+func (deadline Deadline) Fulfill(mode DoneMode) *time.Time {
+	missed := deadline.Missed()
+	if mode == DoneModeOldest && len(missed) != 0 {
+		return &missed[0]
+	}
+
+	typ := deadline.Rule.Type()
+	switch typ {
+	case TaskRuleTypeByTimes, TaskRuleTypeInDates:
+		if len(missed) != 0 {
+			return &missed[len(missed)-1]
+		}
+		return deadline.Next()
+	case TaskRuleTypePeriodic, TaskRuleTypeInDaysEveryMonth, TaskRuleTypeInWeekdays, TaskRuleTypeNone:
+		return nil
+	}
+	panic("unreachable: invalid rule type: " + typ)
+}
+
 // RemainingTime : how much time until task deadline
 func (deadline Deadline) RemainingTime() RemainingTime {
 	latest := deadline.Latest()
@@ -153,5 +303,17 @@ type DoneTask struct {
 
 // DoneTaskData :
 type DoneTaskData interface {
+	// the task name at done
+	Name() string
 	At() time.Time
+	// the due time which the done fulfills, nil if not linked
+	OccurrenceAt() *time.Time
+}
+
+// Fulfilled : the linked due time or the done time
+func (done *DoneTask) Fulfilled() time.Time {
+	if at := done.OccurrenceAt(); at != nil {
+		return *at
+	}
+	return done.At()
 }
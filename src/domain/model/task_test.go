@@ -0,0 +1,457 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDone struct {
+	at           time.Time
+	occurrenceAt *time.Time
+}
+
+func (done testDone) Name() string {
+	return "name"
+}
+
+func (done testDone) At() time.Time {
+	return done.at
+}
+
+func (done testDone) OccurrenceAt() *time.Time {
+	return done.occurrenceAt
+}
+
+func doneAt(at time.Time) *DoneTask {
+	return &DoneTask{DoneTaskData: testDone{at: at}}
+}
+
+func doneFor(at time.Time, occurrenceAt time.Time) *DoneTask {
+	return &DoneTask{DoneTaskData: testDone{at: at, occurrenceAt: &occurrenceAt}}
+}
+
+type testTask struct {
+	rule     *TaskRule
+	startAt  time.Time
+	lastDone *DoneTask
+}
+
+func (task testTask) ID() int {
+	return 1
+}
+
+func (task testTask) Name() string {
+	return "name"
+}
+
+func (task testTask) Notes() string {
+	return ""
+}
+
+func (task testTask) Version() int {
+	return 1
+}
+
+func (task testTask) StartAt() time.Time {
+	return task.startAt
+}
+
+func (task testTask) LastDone() *DoneTask {
+	return task.lastDone
+}
+
+func (task testTask) Rule() *TaskRule {
+	return task.rule
+}
+
+func localTime(year int, month time.Month, day int, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+}
+
+func localEndOfDay(year int, month time.Month, day int) time.Time {
+	return endOfDay(localTime(year, month, day, 0))
+}
+
+func weekdaysRule(weekdays ...Weekday) *TaskRule {
+	return &TaskRule{TaskRuleData: testRule{
+		typ:      TaskRuleTypeInWeekdays,
+		weekdays: weekdays,
+	}}
+}
+
+func byTimesRule(times ...time.Time) *TaskRule {
+	return &TaskRule{TaskRuleData: testRule{
+		typ:       TaskRuleTypeByTimes,
+		dateTimes: times,
+	}}
+}
+
+func TestDeadlineMissed(t *testing.T) {
+	// NOTE: 2020-01-01 is Wednesday.
+	startAt := localTime(2020, 1, 1, 0)
+	for _, c := range []struct {
+		name     string
+		deadline Deadline
+		want     []time.Time
+	}{
+		{
+			name: "monthly from the end of month",
+			deadline: Deadline{
+				Rule:    periodicRule(1, PeriodUnitMonth),
+				StartAt: localTime(2020, 1, 31, 0),
+				Now:     localTime(2020, 4, 15, 0),
+			},
+			// NOTE: Feb 31 is clamped to Feb 29, and the next is Mar 31 without drifting.
+			want: []time.Time{localTime(2020, 2, 29, 0), localTime(2020, 3, 31, 0)},
+		},
+		{
+			name: "monthly from the linked done at the end of month",
+			deadline: Deadline{
+				Rule:     periodicRule(1, PeriodUnitMonth),
+				StartAt:  localTime(2019, 12, 31, 0),
+				LastDone: doneFor(localTime(2020, 2, 10, 0), localTime(2020, 1, 31, 0)),
+				Now:      localTime(2020, 4, 15, 0),
+			},
+			want: []time.Time{localTime(2020, 2, 29, 0), localTime(2020, 3, 31, 0)},
+		},
+		{
+			name: "weekdays without done",
+			deadline: Deadline{
+				Rule:    weekdaysRule(Weekday(time.Monday)),
+				StartAt: startAt,
+				Now:     localTime(2020, 1, 15, 12),
+			},
+			want: []time.Time{localEndOfDay(2020, 1, 6), localEndOfDay(2020, 1, 13)},
+		},
+		{
+			name: "weekdays after the linked done",
+			deadline: Deadline{
+				Rule:     weekdaysRule(Weekday(time.Monday)),
+				StartAt:  startAt,
+				LastDone: doneFor(localTime(2020, 1, 15, 10), localEndOfDay(2020, 1, 6)),
+				Now:      localTime(2020, 1, 15, 12),
+			},
+			want: []time.Time{localEndOfDay(2020, 1, 13)},
+		},
+		{
+			name: "weekdays after the not linked done",
+			deadline: Deadline{
+				Rule:     weekdaysRule(Weekday(time.Monday)),
+				StartAt:  startAt,
+				LastDone: doneAt(localTime(2020, 1, 7, 10)),
+				Now:      localTime(2020, 1, 15, 12),
+			},
+			want: nil,
+		},
+		{
+			name: "by times without done",
+			deadline: Deadline{
+				Rule:    byTimesRule(localTime(2020, 1, 3, 9), localTime(2020, 1, 2, 9), localTime(2020, 1, 4, 9)),
+				StartAt: startAt,
+				Now:     localTime(2020, 1, 5, 0),
+			},
+			want: []time.Time{localTime(2020, 1, 2, 9), localTime(2020, 1, 3, 9), localTime(2020, 1, 4, 9)},
+		},
+		{
+			name: "done",
+			deadline: Deadline{
+				Rule:    byTimesRule(localTime(2020, 1, 2, 9)),
+				StartAt: startAt,
+				Done:    true,
+				Now:     localTime(2020, 1, 5, 0),
+			},
+			want: nil,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.deadline.Missed()
+			if len(got) == 0 && len(c.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDeadlineFulfill(t *testing.T) {
+	startAt := localTime(2020, 1, 1, 0)
+	times := []time.Time{localTime(2020, 1, 2, 9), localTime(2020, 1, 3, 9), localTime(2020, 1, 4, 9), localTime(2020, 1, 6, 9)}
+	for _, c := range []struct {
+		name     string
+		deadline Deadline
+		mode     DoneMode
+		want     *time.Time
+	}{
+		{
+			name: "catch up the multiple missed",
+			deadline: Deadline{
+				Rule:    byTimesRule(times...),
+				StartAt: startAt,
+				Now:     localTime(2020, 1, 5, 0),
+			},
+			mode: DoneModeCatchUp,
+			want: &times[2],
+		},
+		{
+			name: "the oldest of the multiple missed",
+			deadline: Deadline{
+				Rule:    byTimesRule(times...),
+				StartAt: startAt,
+				Now:     localTime(2020, 1, 5, 0),
+			},
+			mode: DoneModeOldest,
+			want: &times[0],
+		},
+		{
+			name: "the oldest after the linked done",
+			deadline: Deadline{
+				Rule:     byTimesRule(times...),
+				StartAt:  startAt,
+				LastDone: doneFor(localTime(2020, 1, 4, 12), times[0]),
+				Now:      localTime(2020, 1, 5, 0),
+			},
+			mode: DoneModeOldest,
+			want: &times[1],
+		},
+		{
+			name: "the next without missed",
+			deadline: Deadline{
+				Rule:    byTimesRule(times...),
+				StartAt: startAt,
+				Now:     localTime(2020, 1, 1, 12),
+			},
+			mode: DoneModeCatchUp,
+			want: &times[0],
+		},
+		{
+			name: "catch up periodic to reschedule",
+			deadline: Deadline{
+				Rule:    periodicRule(1, PeriodUnitMonth),
+				StartAt: localTime(2020, 1, 31, 0),
+				Now:     localTime(2020, 4, 15, 0),
+			},
+			mode: DoneModeCatchUp,
+			want: nil,
+		},
+		{
+			name: "the oldest periodic at the end of month",
+			deadline: Deadline{
+				Rule:    periodicRule(1, PeriodUnitMonth),
+				StartAt: localTime(2020, 1, 31, 0),
+				Now:     localTime(2020, 4, 15, 0),
+			},
+			mode: DoneModeOldest,
+			want: timePtr(localTime(2020, 2, 29, 0)),
+		},
+		{
+			name: "catch up weekdays to reschedule",
+			deadline: Deadline{
+				Rule:    weekdaysRule(Weekday(time.Monday)),
+				StartAt: startAt,
+				Now:     localTime(2020, 1, 15, 12),
+			},
+			mode: DoneModeCatchUp,
+			want: nil,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.deadline.Fulfill(c.mode)
+			if (got == nil) != (c.want == nil) || (got != nil && !got.Equal(*c.want)) {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTaskDoneInWeekdays(t *testing.T) {
+	rule := weekdaysRule(Weekday(time.Monday))
+	startAt := localTime(2020, 1, 1, 0)
+	for _, c := range []struct {
+		name     string
+		lastDone *DoneTask
+		now      time.Time
+		want     bool
+	}{
+		{
+			name:     "done today",
+			lastDone: doneAt(localTime(2020, 1, 13, 10)),
+			now:      localTime(2020, 1, 13, 12),
+			want:     true,
+		},
+		{
+			name:     "done in the previous week",
+			lastDone: doneAt(localTime(2020, 1, 6, 10)),
+			now:      localTime(2020, 1, 13, 12),
+			want:     false,
+		},
+		{
+			// NOTE: judged by the done time, not by the linked occurrence.
+			name:     "done today for the missed occurrence",
+			lastDone: doneFor(localTime(2020, 1, 13, 10), localEndOfDay(2020, 1, 6)),
+			now:      localTime(2020, 1, 13, 12),
+			want:     true,
+		},
+		{
+			name:     "done today not in the weekdays",
+			lastDone: doneAt(localTime(2020, 1, 14, 10)),
+			now:      localTime(2020, 1, 14, 12),
+			want:     false,
+		},
+		{
+			name: "not done",
+			now:  localTime(2020, 1, 13, 12),
+			want: false,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			task := &Task{TaskData: testTask{rule: rule, startAt: startAt, lastDone: c.lastDone}}
+			if got := task.Done(c.now); got != c.want {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTaskOccurrences(t *testing.T) {
+	type want struct {
+		at   time.Time
+		done bool
+	}
+	for _, c := range []struct {
+		name    string
+		rule    *TaskRule
+		startAt time.Time
+		dones   []*DoneTask
+		from    time.Time
+		to      time.Time
+		want    []want
+	}{
+		{
+			name:    "including from and excluding to",
+			rule:    periodicRule(1, PeriodUnitDay),
+			startAt: localTime(2020, 1, 1, 9),
+			from:    localTime(2020, 1, 10, 9),
+			to:      localTime(2020, 1, 12, 9),
+			want: []want{
+				{at: localTime(2020, 1, 10, 9)},
+				{at: localTime(2020, 1, 11, 9)},
+			},
+		},
+		{
+			name:    "monthly long after the start",
+			rule:    periodicRule(1, PeriodUnitMonth),
+			startAt: localTime(2000, 1, 31, 9),
+			from:    localTime(2020, 2, 1, 0),
+			to:      localTime(2020, 4, 1, 0),
+			want: []want{
+				{at: localTime(2020, 2, 29, 9)},
+				{at: localTime(2020, 3, 31, 9)},
+			},
+		},
+		{
+			name:    "periodic restarted by the done before the window",
+			rule:    periodicRule(1, PeriodUnitDay),
+			startAt: localTime(2020, 1, 1, 0),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 5, 18)),
+				doneAt(localTime(2020, 1, 9, 12)),
+			},
+			from: localTime(2020, 1, 10, 0),
+			to:   localTime(2020, 1, 12, 0),
+			want: []want{
+				{at: localTime(2020, 1, 10, 12)},
+				{at: localTime(2020, 1, 11, 12)},
+			},
+		},
+		{
+			name:    "periodic restarted by the done in the window",
+			rule:    periodicRule(1, PeriodUnitDay),
+			startAt: localTime(2020, 1, 1, 0),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 2, 12)),
+			},
+			from: localTime(2020, 1, 2, 0),
+			to:   localTime(2020, 1, 5, 0),
+			want: []want{
+				{at: localTime(2020, 1, 2, 0)},
+				{at: localTime(2020, 1, 3, 0), done: true},
+				{at: localTime(2020, 1, 3, 12)},
+				{at: localTime(2020, 1, 4, 12)},
+			},
+		},
+		{
+			name:    "the first in the window fulfilled by the done before the window",
+			rule:    weekdaysRule(Weekday(time.Monday)),
+			startAt: localTime(2020, 1, 1, 0),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 12, 10)),
+			},
+			from: localTime(2020, 1, 13, 0),
+			to:   localTime(2020, 1, 21, 0),
+			want: []want{
+				{at: localEndOfDay(2020, 1, 13), done: true},
+				{at: localEndOfDay(2020, 1, 20)},
+			},
+		},
+		{
+			name:    "the done before the window fulfilling the one before the window",
+			rule:    weekdaysRule(Weekday(time.Monday)),
+			startAt: localTime(2020, 1, 1, 0),
+			dones: []*DoneTask{
+				doneAt(localTime(2020, 1, 6, 10)),
+			},
+			from: localTime(2020, 1, 7, 0),
+			to:   localTime(2020, 1, 14, 0),
+			want: []want{
+				{at: localEndOfDay(2020, 1, 13)},
+			},
+		},
+		{
+			name:    "linked done before the window",
+			rule:    byTimesRule(localTime(2020, 1, 3, 9), localTime(2020, 1, 4, 9)),
+			startAt: localTime(2020, 1, 1, 0),
+			dones: []*DoneTask{
+				doneFor(localTime(2020, 1, 1, 10), localTime(2020, 1, 3, 9)),
+			},
+			from: localTime(2020, 1, 3, 0),
+			to:   localTime(2020, 1, 5, 0),
+			want: []want{
+				{at: localTime(2020, 1, 3, 9), done: true},
+				{at: localTime(2020, 1, 4, 9)},
+			},
+		},
+		{
+			name:    "window before the start",
+			rule:    periodicRule(1, PeriodUnitDay),
+			startAt: localTime(2020, 1, 10, 0),
+			from:    localTime(2020, 1, 1, 0),
+			to:      localTime(2020, 1, 12, 0),
+			want: []want{
+				{at: localTime(2020, 1, 11, 0)},
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			dones := []DoneTask{}
+			for _, d := range c.dones {
+				dones = append(dones, *d)
+			}
+			task := &Task{TaskData: testTask{rule: c.rule, startAt: c.startAt}}
+
+			got := []want{}
+			for _, o := range task.Occurrences(c.from, c.to, dones) {
+				got = append(got, want{at: o.At, done: o.Done})
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, but: %v", c.want, got)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
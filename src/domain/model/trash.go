@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// TrashedTask : a deleted task which can be restored until purged
+type TrashedTask struct {
+	Task
+	DeletedAt time.Time
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/notomo/counteria.nvim/src/domain/model"
+
+// BackupRepository :
+type BackupRepository interface {
+	Backup(model.BackupReason) (*model.Backup, error)
+	// newest first
+	List() ([]model.Backup, error)
+	Restore(id int) error
+}
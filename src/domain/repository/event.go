@@ -0,0 +1,19 @@
+package repository
+
+import "github.com/notomo/counteria.nvim/src/domain/model"
+
+// EventRepository : the append only log of the task changes
+type EventRepository interface {
+	// newest first
+	List(EventListOption) ([]model.TaskEvent, error)
+	Count(EventListOption) (int, error)
+	One(id int) (*model.TaskEvent, error)
+}
+
+// EventListOption :
+type EventListOption struct {
+	// all tasks if 0
+	TaskID int
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+// HistoryFilter : empty fields match all
+type HistoryFilter struct {
+	// inclusive
+	From *time.Time
+	// exclusive
+	To      *time.Time
+	TaskIDs []int
+}
+
+// MatchTask :
+func (filter HistoryFilter) MatchTask(taskID int) bool {
+	if len(filter.TaskIDs) == 0 {
+		return true
+	}
+	for _, id := range filter.TaskIDs {
+		if id == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDone :
+func (filter HistoryFilter) MatchDone(at time.Time) bool {
+	if filter.From != nil && at.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !at.Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+// Entries : the matched dones in the task history
+// NOTE: history must be ordered by done time.
+func (filter HistoryFilter) Entries(task model.Task, history []model.DoneTask) model.HistoryEntries {
+	entries := model.HistoryEntries{}
+	if !filter.MatchTask(task.ID()) {
+		return entries
+	}
+	var previous *model.DoneTask
+	for i := range history {
+		done := history[i]
+		if filter.MatchDone(done.At()) {
+			entries = append(entries, model.HistoryEntry{
+				Task:     task,
+				Done:     done,
+				Previous: previous,
+			})
+		}
+		previous = &history[i]
+	}
+	return entries
+}
@@ -1,5 +1,13 @@
 package repository
 
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
 // Sort :
 type Sort struct {
 	By    SortBy
@@ -14,6 +22,8 @@ var (
 	SortByTaskRemains = SortBy("TaskRemains")
 	// SortByTaskDoneAt :
 	SortByTaskDoneAt = SortBy("TaskDoneAt")
+	// SortByTaskName :
+	SortByTaskName = SortBy("TaskName")
 )
 
 // SortOrder : asc or desc
@@ -26,9 +36,165 @@ var (
 	SortOrderDesc = SortOrder("DESC")
 )
 
+// Apply : sort the tasks in place
+func (s Sort) Apply(tasks []model.Task, now time.Time) {
+	sorter := s.sorter(tasks, now)
+	if s.Order == SortOrderDesc {
+		sorter = sort.Reverse(sorter)
+	}
+	sort.Stable(sorter)
+}
+
+func (s Sort) sorter(tasks []model.Task, now time.Time) sort.Interface {
+	switch s.By {
+	case SortByTaskRemains:
+		// NOTE: the deadline is calculated once per task
+		latests := make([]*time.Time, len(tasks))
+		for i, task := range tasks {
+			latests[i] = task.Deadline(now).Latest()
+		}
+		return &remainsSorter{tasks: tasks, latests: latests}
+	case SortByTaskDoneAt:
+		// NOTE: not done tasks first
+		return &taskSorter{tasks: tasks, less: func(i, j int) bool {
+			doneAtI := tasks[i].DoneAt()
+			doneAtJ := tasks[j].DoneAt()
+			if doneAtJ == nil {
+				return false
+			}
+			if doneAtI == nil {
+				return true
+			}
+			return doneAtI.Before(*doneAtJ)
+		}}
+	case SortByTaskName:
+		return &taskSorter{tasks: tasks, less: func(i, j int) bool {
+			return tasks[i].Name() < tasks[j].Name()
+		}}
+	}
+	panic("invalid sort by: " + s.By)
+}
+
+type taskSorter struct {
+	tasks []model.Task
+	less  func(i, j int) bool
+}
+
+func (s *taskSorter) Len() int           { return len(s.tasks) }
+func (s *taskSorter) Less(i, j int) bool { return s.less(i, j) }
+func (s *taskSorter) Swap(i, j int)      { s.tasks[i], s.tasks[j] = s.tasks[j], s.tasks[i] }
+
+// remainsSorter : swaps the latest deadlines with the tasks
+type remainsSorter struct {
+	tasks   []model.Task
+	latests []*time.Time
+}
+
+func (s *remainsSorter) Len() int { return len(s.tasks) }
+
+// NOTE: no deadline is later than any deadline
+func (s *remainsSorter) Less(i, j int) bool {
+	latestI := s.latests[i]
+	if latestI == nil {
+		return false
+	}
+	latestJ := s.latests[j]
+	if latestJ == nil {
+		return true
+	}
+	return latestI.Unix() < latestJ.Unix()
+}
+
+func (s *remainsSorter) Swap(i, j int) {
+	s.tasks[i], s.tasks[j] = s.tasks[j], s.tasks[i]
+	s.latests[i], s.latests[j] = s.latests[j], s.latests[i]
+}
+
+// Filter : conditions to narrow tasks, the zero value matches all tasks
+type Filter struct {
+	RuleTypes []model.TaskRuleType
+	// name substring, ignoring case by strings.ToLower on every datastore
+	Name string
+	// start date range [StartFrom, StartTo)
+	StartFrom *time.Time
+	StartTo   *time.Time
+
+	// active now
+	Active bool
+	// deadline passed
+	Overdue bool
+	// done in the current period
+	Done bool
+}
+
+// HasState : whether the filter depends on the current time
+func (filter Filter) HasState() bool {
+	return filter.Active || filter.Overdue || filter.Done
+}
+
+// MatchState : test only Active, Overdue, Done
+func (filter Filter) MatchState(task model.Task, now time.Time) bool {
+	if filter.Active && !task.IsActive(now) {
+		return false
+	}
+	if filter.Done && !task.Done(now) {
+		return false
+	}
+	if filter.Overdue {
+		deadline := task.Deadline(now)
+		if deadline.Done || deadline.RemainingTime().Exists() {
+			return false
+		}
+	}
+	return true
+}
+
+// Match : test all conditions
+func (filter Filter) Match(task model.Task, now time.Time) bool {
+	if len(filter.RuleTypes) != 0 {
+		matched := false
+		typ := task.Rule().Type()
+		for _, t := range filter.RuleTypes {
+			if t == typ {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if filter.Name != "" && !strings.Contains(strings.ToLower(task.Name()), strings.ToLower(filter.Name)) {
+		return false
+	}
+	startAt := task.StartAt()
+	if filter.StartFrom != nil && startAt.Before(*filter.StartFrom) {
+		return false
+	}
+	if filter.StartTo != nil && !startAt.Before(*filter.StartTo) {
+		return false
+	}
+	return filter.MatchState(task, now)
+}
+
 // ListOption :
 type ListOption struct {
 	Sort   Sort
+	Filter Filter
+	// no limit if zero
 	Limit  int
 	Offset int
 }
+
+// Range : start and end index of the list having the length
+func (option ListOption) Range(length int) (int, int) {
+	start := option.Offset
+	if start > length {
+		start = length
+	}
+	end := length
+	if option.Limit > 0 && start+option.Limit < end {
+		end = start + option.Limit
+	}
+	return start, end
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+// SearchWords : search without full text index
+// NOTE: every word should be a prefix of a word in the texts ignoring case.
+type SearchWords []string
+
+// NewSearchWords :
+func NewSearchWords(words string) SearchWords {
+	unique := SearchWords{}
+	seen := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(words)) {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		unique = append(unique, w)
+	}
+	return unique
+}
+
+// Match : returns the matched fields and the number of matches
+func (words SearchWords) Match(texts ...model.SearchField) ([]model.SearchField, int, bool) {
+	if len(words) == 0 {
+		return nil, 0, false
+	}
+
+	matched := make(map[string]bool)
+	fields := []model.SearchField{}
+	count := 0
+	for _, field := range texts {
+		lower := strings.ToLower(field.Text)
+		for _, w := range words {
+			for offset := 0; ; {
+				index := strings.Index(lower[offset:], w)
+				if index == -1 {
+					break
+				}
+				start := offset + index
+				offset = start + len(w)
+				if !wordStart(lower, start) {
+					continue
+				}
+				field.Matches = append(field.Matches, model.TextRange{Start: start, End: start + len(w)})
+				matched[w] = true
+			}
+		}
+		if len(field.Matches) == 0 {
+			continue
+		}
+		sort.Slice(field.Matches, func(i, j int) bool {
+			return field.Matches[i].Start < field.Matches[j].Start
+		})
+		count += len(field.Matches)
+		fields = append(fields, field)
+	}
+	return fields, count, len(matched) == len(words)
+}
+
+func wordStart(text string, index int) bool {
+	if index == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:index])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// SearchTexts : the searched fields of the task
+func SearchTexts(task model.Task) []model.SearchField {
+	return []model.SearchField{
+		{Name: "name", Text: task.Name()},
+		{Name: "notes", Text: task.Notes()},
+	}
+}
+
+// SortHits : by the number of matches
+func SortHits(hits []model.SearchHit, counts map[int]int) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return counts[hits[i].Task.ID()] > counts[hits[j].Task.ID()]
+	})
+}
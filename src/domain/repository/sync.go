@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+// SyncRepository :
+type SyncRepository interface {
+	// merge with the database file in both directions
+	Sync(path string, now time.Time) (*model.SyncResult, error)
+}
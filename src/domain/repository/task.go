@@ -9,10 +9,27 @@ import (
 // TaskRepository :
 type TaskRepository interface {
 	List(opt ListOption, now time.Time) ([]model.Task, error)
-	Create(Transaction, *model.Task) error
-	Update(Transaction, *model.Task) error
-	Delete(Transaction, *model.Task) error
-	Done(Transaction, *model.Task, time.Time) error
+	Count(opt ListOption, now time.Time) (int, error)
+	Create(Transaction, *model.Task, time.Time) error
+	Update(Transaction, *model.Task, time.Time) error
+	// move to the trash
+	Delete(Transaction, *model.Task, time.Time) error
+	Done(Transaction, *model.Task, time.Time, *time.Time) error
+	// add the dones except at the same times as the stored ones, without the events and the version change
+	ImportHistory(Transaction, *model.Task, []model.DoneTask) (int, error)
 	One(id int) (*model.Task, error)
 	Temporary(now time.Time) *model.Task
+	Occurrences(from time.Time, to time.Time) (model.Occurrences, error)
+	SearchTasks(words string) ([]model.SearchHit, error)
+	History(taskID int) ([]model.DoneTask, error)
+	// the dones of all tasks ordered by done time
+	ListHistory(HistoryFilter) (model.HistoryEntries, error)
+	// ordered by deleted at desc
+	Trash() ([]model.TrashedTask, error)
+	// restore the trashed task by the id
+	Restore(Transaction, int, time.Time) error
+	// delete the trashed task by the id with the done history
+	Purge(Transaction, int, time.Time) error
+	// purge the tasks trashed before the first time
+	PurgeBefore(Transaction, time.Time, time.Time) (int, error)
 }
@@ -1,8 +1,16 @@
 package repository
 
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 // TransactionFactory :
 type TransactionFactory interface {
 	Begin() (Transaction, error)
+	// Do : run the work as a unit of work, see RunUnitOfWork
+	Do(work func(Transaction) error, afterCommits ...func() error) error
 }
 
 // Transaction :
@@ -10,3 +18,54 @@ type Transaction interface {
 	Commit() error
 	Rollback() error
 }
+
+// RunUnitOfWork : run the work in a transaction and commit it
+// The transaction is rolled back if the work returns an error or panics.
+// The whole work is retried after each wait while retryable returns true,
+// so the work must not have side effects other than the transaction.
+// afterCommits are called in order only once after the commit.
+func RunUnitOfWork(begin func() (Transaction, error), waits []time.Duration, retryable func(error) bool, work func(Transaction) error, afterCommits ...func() error) error {
+	for i := 0; ; i++ {
+		err := runOnce(begin, work)
+		if err == nil {
+			break
+		}
+		if i == len(waits) || retryable == nil || !retryable(err) {
+			return err
+		}
+		time.Sleep(waits[i])
+	}
+
+	for _, afterCommit := range afterCommits {
+		if err := afterCommit(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func runOnce(begin func() (Transaction, error), work func(Transaction) error) error {
+	transaction, err := begin()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			// NOTE: the panic is more important than the rollback error
+			_ = transaction.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := work(transaction); err != nil {
+		if err := transaction.Rollback(); err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(err)
+	}
+	if err := transaction.Commit(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package lib
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+)
+
+// NewUUID : random (version 4)
+func NewUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("unreachable: failed to read random bytes: " + err.Error())
+	}
+	return formatUUID(b, 4)
+}
+
+// namespace of NameUUID: afa364b3-766e-4aad-9017-62e33f52cc4b
+var uuidNamespace = [16]byte{0xaf, 0xa3, 0x64, 0xb3, 0x76, 0x6e, 0x4a, 0xad, 0x90, 0x17, 0x62, 0xe3, 0x3f, 0x52, 0xcc, 0x4b}
+
+// NameUUID : the same name has the same uuid (version 5, RFC 4122)
+func NameUUID(name string) string {
+	hash := sha1.Sum(append(uuidNamespace[:], name...))
+	return formatUUID(hash[:16], 5)
+}
+
+func formatUUID(b []byte, version byte) string {
+	b[6] = (b[6] & 0x0f) | version<<4
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,13 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestNameUUID(t *testing.T) {
+	// NOTE: the same as uuid5(afa364b3-766e-4aad-9017-62e33f52cc4b, "task:1:2") by the other implementations
+	want := "75a90de3-5c37-5bfa-b180-50fc520b729d"
+	if got := NameUUID("task:1:2"); got != want {
+		t.Errorf("want %s, but: %s", want, got)
+	}
+}
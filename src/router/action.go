@@ -2,13 +2,24 @@ package router
 
 import (
 	"strings"
+	"time"
 
+	"github.com/notomo/counteria.nvim/src/command"
 	"github.com/notomo/counteria.nvim/src/router/route"
 	"github.com/notomo/counteria.nvim/src/vimlib"
 	"github.com/pkg/errors"
 )
 
 func (router *Router) do(args []string) error {
+	if len(args) != 0 {
+		switch args[0] {
+		case "next":
+			return router.move(1)
+		case "prev":
+			return router.move(-1)
+		}
+	}
+
 	state, err := router.BufferClientFactory.Current().LoadLineState()
 	if err != nil {
 		if errors.Cause(err) == vimlib.ErrNoState {
@@ -26,6 +37,11 @@ func (router *Router) do(args []string) error {
 		case "done":
 			method = route.MethodWrite
 			p = p + "/done"
+		case "restore":
+			method = route.MethodWrite
+			p = p + "/restore"
+		case "log":
+			p = p + "/log"
 		default:
 			return route.NewErrInvalidAction(strings.Join(args, " "))
 		}
@@ -38,6 +54,70 @@ func (router *Router) do(args []string) error {
 	return nil
 }
 
+// move : to the next or previous page of the current buffer
+// NOTE: stays at the last page quietly.
+func (router *Router) move(diff int) error {
+	client := router.BufferClientFactory.Current()
+	path, err := client.Path()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req, err := route.All.Match(route.MethodRead, path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	params := req.Params
+	switch req.Route.Path {
+	case route.TasksList.Path, route.TasksListPage.Path:
+		page := 1
+		if req.Route.Path == route.TasksListPage.Path {
+			page = params.Page()
+		}
+		if page+diff < 1 {
+			return route.NewErrInvalidAction("no previous page")
+		}
+		pageCount, err := router.Root.TaskCmd(client.Bufnr).PageCount(req.Query)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if pageCount < page+diff {
+			return nil
+		}
+		pagePath, err := route.TasksListPagePath(page+diff, req.Query)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return router.Redirector.ToPath(route.MethodRead, pagePath)
+	case route.Log.Path, route.LogPage.Path:
+		page := 1
+		if req.Route.Path == route.LogPage.Path {
+			page = params.Page()
+		}
+		if page+diff < 1 {
+			return route.NewErrInvalidAction("no previous page")
+		}
+		pageCount, err := router.Root.LogCmd(client.Bufnr).PageCount()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if pageCount < page+diff {
+			return nil
+		}
+		pagePath, err := route.LogPagePath(page + diff)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return router.Redirector.ToPath(route.MethodRead, pagePath)
+	case route.Calendar.Path:
+		month := time.Date(params.Year(), params.Month()+time.Month(diff), 1, 0, 0, 0, 0, time.Local)
+		return router.Redirector.ToCalendar(month.Year(), month.Month())
+	}
+
+	return route.NewErrInvalidAction(path)
+}
+
 func (router *Router) open(args []string) error {
 	path := route.Schema + strings.Join(args, "")
 	if err := router.Redirector.ToPath(route.MethodRead, path); err != nil {
@@ -46,3 +126,35 @@ func (router *Router) open(args []string) error {
 
 	return nil
 }
+
+// restore : open the backups to choose
+func (router *Router) restore(args []string) error {
+	if len(args) != 0 {
+		return route.NewErrInvalidAction("restore " + strings.Join(args, " "))
+	}
+	return router.Redirector.ToBackups()
+}
+
+// `:Counteria profile [{name}]` : switch to the profile and open the tasks, or show the active one
+func (router *Router) profile(args []string) error {
+	switch len(args) {
+	case 0:
+		return router.Renderer.Info("profile: " + router.Root.ActiveProfile())
+	case 1:
+	default:
+		return route.NewErrInvalidAction("profile " + strings.Join(args, " "))
+	}
+
+	if err := router.Root.SwitchProfile(args[0]); err != nil {
+		if errors.Cause(err) == command.ErrInvalidProfile {
+			return route.NewErrInvalidAction("profile " + args[0])
+		}
+		return errors.WithStack(err)
+	}
+
+	path, err := route.TasksListPath(route.Query{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return router.Redirector.ToPath(route.MethodRead, path)
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/notomo/counteria.nvim/src/command/datacmd"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/pkg/errors"
+)
+
+// `:Counteria export {path}`
+func (router *Router) export(args []string) error {
+	if len(args) != 1 {
+		return route.NewErrInvalidAction("export " + strings.Join(args, " "))
+	}
+	return router.exportFile(args[0], router.Root.DataCmd().Export)
+}
+
+// `:Counteria export-calendar {path}`
+func (router *Router) exportCalendar(args []string) error {
+	if len(args) != 1 {
+		return route.NewErrInvalidAction("export-calendar " + strings.Join(args, " "))
+	}
+	return router.exportFile(args[0], router.Root.DataCmd().ExportCalendar)
+}
+
+// `:Counteria export-history {path} [from={yyyy-mm-dd}] [to={yyyy-mm-dd}] [task={id,...}]`
+func (router *Router) exportHistory(args []string) error {
+	if len(args) == 0 {
+		return route.NewErrInvalidAction("export-history")
+	}
+
+	options := map[string]string{
+		"from": "",
+		"to":   "",
+		"task": "",
+	}
+	for _, arg := range args[1:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if _, ok := options[kv[0]]; !ok || len(kv) != 2 {
+			return route.NewErrInvalidAction("export-history " + arg)
+		}
+		options[kv[0]] = kv[1]
+	}
+	filter, err := datacmd.ParseHistoryFilter(options["from"], options["to"], options["task"])
+	if err != nil {
+		return route.NewErrInvalidAction("export-history " + err.Error())
+	}
+
+	return router.exportFile(args[0], func(w io.Writer) error {
+		return router.Root.DataCmd().ExportHistory(w, filter)
+	})
+}
+
+func (router *Router) exportFile(rawPath string, export func(io.Writer) error) error {
+	var path string
+	if err := router.Vim.Call("expand", &path, rawPath); err != nil {
+		return errors.WithStack(err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := export(f); err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return router.Renderer.Info("exported: " + path)
+}
@@ -9,6 +9,12 @@ var (
 	ErrInvalidAction = fmt.Errorf("invalid action")
 	// ErrValidation : create, update validation failed
 	ErrValidation = fmt.Errorf("validation")
+	// ErrInvalidQuery : 400
+	ErrInvalidQuery = fmt.Errorf("invalid query")
+	// ErrBusy : 503
+	ErrBusy = fmt.Errorf("busy")
+	// ErrConflict : 409
+	ErrConflict = fmt.Errorf("conflict")
 )
 
 // Err :
@@ -37,3 +43,18 @@ func NewErrInvalidAction(action string) error {
 func NewErrValidation(err error) error {
 	return &Err{Err: ErrValidation, Arg: err.Error()}
 }
+
+// NewErrInvalidQuery :
+func NewErrInvalidQuery(query string) error {
+	return &Err{Err: ErrInvalidQuery, Arg: query, IsWarn: true}
+}
+
+// NewErrBusy :
+func NewErrBusy() error {
+	return &Err{Err: ErrBusy, Arg: "the data is being changed by another counteria, try again later", IsWarn: true}
+}
+
+// NewErrConflict :
+func NewErrConflict(path string) error {
+	return &Err{Err: ErrConflict, Arg: path + " was changed by others, reload and try again", IsWarn: true}
+}
@@ -0,0 +1,198 @@
+package route
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+)
+
+// QuerySort : `sort=`
+type QuerySort string
+
+var (
+	// QuerySortRemains :
+	QuerySortRemains = QuerySort("remains")
+	// QuerySortDoneAt :
+	QuerySortDoneAt = QuerySort("doneAt")
+	// QuerySortName :
+	QuerySortName = QuerySort("name")
+)
+
+// QueryOrder : `order=`
+type QueryOrder string
+
+var (
+	// QueryOrderAsc :
+	QueryOrderAsc = QueryOrder("asc")
+	// QueryOrderDesc :
+	QueryOrderDesc = QueryOrder("desc")
+)
+
+// QueryFilter : `filter=`
+type QueryFilter string
+
+var (
+	// QueryFilterActive :
+	QueryFilterActive = QueryFilter("active")
+	// QueryFilterOverdue :
+	QueryFilterOverdue = QueryFilter("overdue")
+	// QueryFilterDone :
+	QueryFilterDone = QueryFilter("done")
+)
+
+const queryDateFormat = "2006-01-02"
+
+// Query : typed query string
+// e.g. `q=xxx&sort=name&order=asc&filter=overdue,active&rule=periodic&name=xxx&startFrom=2020-01-01&startTo=2020-02-01`
+type Query struct {
+	Sort      QuerySort
+	Order     QueryOrder
+	Filters   []QueryFilter
+	Rules     []model.TaskRuleType
+	Name      string
+	StartFrom *time.Time
+	StartTo   *time.Time
+	// search words
+	Words string
+}
+
+// HasFilter :
+func (query Query) HasFilter(filter QueryFilter) bool {
+	for _, f := range query.Filters {
+		if f == filter {
+			return true
+		}
+	}
+	return false
+}
+
+// String : encode in the fixed key order
+func (query Query) String() string {
+	parts := []string{}
+	add := func(key string, values ...string) {
+		escaped := []string{}
+		for _, v := range values {
+			if v != "" {
+				escaped = append(escaped, url.QueryEscape(v))
+			}
+		}
+		if len(escaped) == 0 {
+			return
+		}
+		parts = append(parts, key+"="+strings.Join(escaped, ","))
+	}
+
+	add("q", query.Words)
+	add("sort", string(query.Sort))
+	add("order", string(query.Order))
+	filters := []string{}
+	for _, f := range query.Filters {
+		filters = append(filters, string(f))
+	}
+	add("filter", filters...)
+	rules := []string{}
+	for _, r := range query.Rules {
+		rules = append(rules, r.String())
+	}
+	add("rule", rules...)
+	add("name", query.Name)
+	if query.StartFrom != nil {
+		add("startFrom", query.StartFrom.Format(queryDateFormat))
+	}
+	if query.StartTo != nil {
+		add("startTo", query.StartTo.Format(queryDateFormat))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// NOTE: unknown keys are ignored
+func parseQuery(rawQuery string) (Query, error) {
+	var query Query
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return query, NewErrInvalidQuery(rawQuery)
+	}
+
+	if v := values.Get("sort"); v != "" {
+		sort := QuerySort(v)
+		switch sort {
+		case QuerySortRemains, QuerySortDoneAt, QuerySortName:
+			query.Sort = sort
+		default:
+			return query, NewErrInvalidQuery("sort=" + v)
+		}
+	}
+
+	if v := values.Get("order"); v != "" {
+		order := QueryOrder(v)
+		switch order {
+		case QueryOrderAsc, QueryOrderDesc:
+			query.Order = order
+		default:
+			return query, NewErrInvalidQuery("order=" + v)
+		}
+	}
+
+	for _, v := range splitValues(values["filter"]) {
+		filter := QueryFilter(v)
+		switch filter {
+		case QueryFilterActive, QueryFilterOverdue, QueryFilterDone:
+			query.Filters = append(query.Filters, filter)
+		default:
+			return query, NewErrInvalidQuery("filter=" + v)
+		}
+	}
+
+	for _, v := range splitValues(values["rule"]) {
+		typ, ok := ruleType(v)
+		if !ok {
+			return query, NewErrInvalidQuery("rule=" + v)
+		}
+		query.Rules = append(query.Rules, typ)
+	}
+
+	query.Name = values.Get("name")
+	query.Words = values.Get("q")
+
+	for key, target := range map[string]**time.Time{
+		"startFrom": &query.StartFrom,
+		"startTo":   &query.StartTo,
+	} {
+		v := values.Get(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.ParseInLocation(queryDateFormat, v, time.Local)
+		if err != nil {
+			return query, NewErrInvalidQuery(key + "=" + v)
+		}
+		*target = &t
+	}
+
+	return query, nil
+}
+
+func ruleType(value string) (model.TaskRuleType, bool) {
+	for _, typ := range model.TaskRuleTypes() {
+		if typ.String() == value {
+			return typ, true
+		}
+	}
+	return "", false
+}
+
+// splitValues : accept both `key=a,b` and `key=a&key=b`
+func splitValues(values []string) []string {
+	splitted := []string{}
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v != "" {
+				splitted = append(splitted, v)
+			}
+		}
+	}
+	return splitted
+}
@@ -1,6 +1,8 @@
 package route
 
 import (
+	"time"
+
 	"github.com/neovim/go-client/nvim"
 	"github.com/notomo/counteria.nvim/src/vimlib"
 	"github.com/pkg/errors"
@@ -14,7 +16,7 @@ type Redirector struct {
 
 // To : redirect by route
 func (re *Redirector) To(method Method, r Route, params Params) error {
-	path, err := r.BuildPath(params)
+	path, err := r.BuildPath(params, Query{})
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -64,3 +66,30 @@ func (re *Redirector) ToTasksOne(taskID int) error {
 func (re *Redirector) ToTasksList() error {
 	return re.To(MethodRead, TasksList, Params{})
 }
+
+// ToListOf : to the list page if the path is the one, otherwise to the tasks list
+func (re *Redirector) ToListOf(path string) error {
+	if _, err := Lists.Match(MethodRead, path); err != nil {
+		return re.ToTasksList()
+	}
+	return re.ToPath(MethodRead, path)
+}
+
+// ToCalendar :
+func (re *Redirector) ToCalendar(year int, month time.Month) error {
+	path, err := CalendarPath(year, month)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return re.ToPath(MethodRead, path)
+}
+
+// ToBackups :
+func (re *Redirector) ToBackups() error {
+	return re.To(MethodRead, Backups, Params{})
+}
+
+// ToTrash :
+func (re *Redirector) ToTrash() error {
+	return re.To(MethodRead, Trash, Params{})
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -82,8 +83,8 @@ func (r Route) Match(method Method, path string) (Params, bool) {
 	return params, true
 }
 
-// BuildPath :
-func (r Route) BuildPath(params Params) (string, error) {
+// BuildPath : with the query string if not empty
+func (r Route) BuildPath(params Params, query Query) (string, error) {
 	if strings.Count(r.Path, "/:") != len(params) {
 		return "", errors.Errorf("invalid params: %s", params)
 	}
@@ -101,6 +102,9 @@ func (r Route) BuildPath(params Params) (string, error) {
 		return "", errors.Errorf("build path: %s", replaced)
 	}
 
+	if rawQuery := query.String(); rawQuery != "" {
+		return replaced + "?" + rawQuery, nil
+	}
 	return replaced, nil
 }
 
@@ -114,8 +118,38 @@ var (
 	TasksOne = newRoute(Schema+"tasks/:taskId", MethodRead, MethodWrite, MethodDelete)
 	// TasksOneDone :
 	TasksOneDone = newRoute(Schema+"tasks/:taskId/done", MethodWrite)
-	// TasksList :
+	// TasksList : the first page
 	TasksList = newRoute(Schema+"tasks", MethodRead)
+	// TasksListPage :
+	TasksListPage = newRoute(Schema+"tasks/pages/:page", MethodRead)
+	// Agenda : tasks grouped by deadline
+	Agenda = newRoute(Schema+"agenda", MethodRead)
+	// Calendar : month calendar
+	Calendar = newRoute(Schema+"calendar/:year/:month", MethodRead)
+	// CalendarDay : tasks due in the day
+	CalendarDay = newRoute(Schema+"calendar/:year/:month/:day", MethodRead)
+	// Search : tasks matched by `q=`
+	Search = newRoute(Schema+"search", MethodRead)
+	// Backups : datastore backups
+	Backups = newRoute(Schema+"backups", MethodRead)
+	// BackupsOne : only for the line states
+	BackupsOne = newRoute(Schema + "backups/:backupId")
+	// BackupsOneRestore :
+	BackupsOneRestore = newRoute(Schema+"backups/:backupId/restore", MethodWrite)
+	// Log : the first page of the task events
+	Log = newRoute(Schema+"log", MethodRead)
+	// LogPage :
+	LogPage = newRoute(Schema+"log/pages/:page", MethodRead)
+	// LogOne : the snapshots of the event
+	LogOne = newRoute(Schema+"log/:eventId", MethodRead)
+	// TasksOneLog : the task events
+	TasksOneLog = newRoute(Schema+"tasks/:taskId/log", MethodRead)
+	// Trash : deleted tasks
+	Trash = newRoute(Schema+"trash", MethodRead)
+	// TrashOne : purge
+	TrashOne = newRoute(Schema+"trash/:taskId", MethodDelete)
+	// TrashOneRestore :
+	TrashOneRestore = newRoute(Schema+"trash/:taskId/restore", MethodWrite)
 )
 
 // Params :
@@ -130,6 +164,44 @@ func (params Params) TaskID() int {
 	return id
 }
 
+// BackupID :
+func (params Params) BackupID() int {
+	return params.number("backupId")
+}
+
+// EventID :
+func (params Params) EventID() int {
+	return params.number("eventId")
+}
+
+// Page :
+func (params Params) Page() int {
+	return params.number("page")
+}
+
+// Year :
+func (params Params) Year() int {
+	return params.number("year")
+}
+
+// Month :
+func (params Params) Month() time.Month {
+	return time.Month(params.number("month"))
+}
+
+// Day :
+func (params Params) Day() int {
+	return params.number("day")
+}
+
+func (params Params) number(key string) int {
+	n, err := strconv.Atoi(params[key])
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
 // Routes :
 type Routes []Route
 
@@ -139,6 +211,30 @@ var All = Routes{
 	TasksOne,
 	TasksOneDone,
 	TasksList,
+	TasksListPage,
+	Agenda,
+	Calendar,
+	CalendarDay,
+	Search,
+	Backups,
+	BackupsOne,
+	BackupsOneRestore,
+	Log,
+	LogPage,
+	LogOne,
+	TasksOneLog,
+	Trash,
+	TrashOne,
+	TrashOneRestore,
+}
+
+// Lists : task list pages
+var Lists = Routes{
+	TasksList,
+	TasksListPage,
+	Agenda,
+	CalendarDay,
+	Search,
 }
 
 // Events : all events
@@ -146,30 +242,101 @@ var Events = Routes{}
 
 // Match :
 func (routes Routes) Match(method Method, path string) (Request, error) {
+	base, rawQuery := SplitQuery(path)
+	query, err := parseQuery(rawQuery)
+	if err != nil {
+		return Request{}, err
+	}
+
 	for _, r := range routes {
-		params, ok := r.Match(method, path)
+		params, ok := r.Match(method, base)
 		if ok {
 			return Request{
 				Path:   path,
 				Method: method,
 				Route:  r,
 				Params: params,
+				Query:  query,
 			}, nil
 		}
 	}
 	return Request{}, NewErrNotFound(path)
 }
 
+// SplitQuery : split the path into the base and the raw query
+func SplitQuery(path string) (string, string) {
+	index := strings.Index(path, "?")
+	if index == -1 {
+		return path, ""
+	}
+	return path[:index], path[index+1:]
+}
+
 // Request :
 type Request struct {
 	Path   string
 	Method Method
 	Route  Route
 	Params Params
+	Query  Query
 }
 
 // TasksOnePath :
 func TasksOnePath(taskID int) (string, error) {
 	params := Params{"taskId": strconv.Itoa(taskID)}
-	return TasksOne.BuildPath(params)
+	return TasksOne.BuildPath(params, Query{})
+}
+
+// TasksListPath : the first page
+func TasksListPath(query Query) (string, error) {
+	return TasksList.BuildPath(Params{}, query)
+}
+
+// TasksListPagePath :
+func TasksListPagePath(page int, query Query) (string, error) {
+	params := Params{"page": strconv.Itoa(page)}
+	return TasksListPage.BuildPath(params, query)
+}
+
+// CalendarPath :
+func CalendarPath(year int, month time.Month) (string, error) {
+	params := Params{
+		"year":  strconv.Itoa(year),
+		"month": strconv.Itoa(int(month)),
+	}
+	return Calendar.BuildPath(params, Query{})
+}
+
+// CalendarDayPath :
+func CalendarDayPath(year int, month time.Month, day int) (string, error) {
+	params := Params{
+		"year":  strconv.Itoa(year),
+		"month": strconv.Itoa(int(month)),
+		"day":   strconv.Itoa(day),
+	}
+	return CalendarDay.BuildPath(params, Query{})
+}
+
+// BackupsOnePath :
+func BackupsOnePath(backupID int) (string, error) {
+	params := Params{"backupId": strconv.Itoa(backupID)}
+	return BackupsOne.BuildPath(params, Query{})
+}
+
+// LogPagePath :
+func LogPagePath(page int) (string, error) {
+	params := Params{"page": strconv.Itoa(page)}
+	return LogPage.BuildPath(params, Query{})
+}
+
+// LogOnePath :
+func LogOnePath(eventID int) (string, error) {
+	params := Params{"eventId": strconv.Itoa(eventID)}
+	return LogOne.BuildPath(params, Query{})
+}
+
+// TrashOnePath :
+func TrashOnePath(taskID int) (string, error) {
+	params := Params{"taskId": strconv.Itoa(taskID)}
+	return TrashOne.BuildPath(params, Query{})
 }
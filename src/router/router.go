@@ -47,11 +47,24 @@ func (router *Router) Do(args []string) error {
 		subRoute = router.open
 	case "do":
 		subRoute = router.do
+	case "restore":
+		subRoute = router.restore
+	case "profile":
+		subRoute = router.profile
+	case "export":
+		subRoute = router.export
+	case "export-calendar":
+		subRoute = router.exportCalendar
+	case "export-history":
+		subRoute = router.exportHistory
 	default:
 		return route.NewErrInvalidAction(name)
 	}
 
 	if err := subRoute(args[1:]); err != nil {
+		if errors.Cause(err) == domain.ErrBusy {
+			return route.NewErrBusy()
+		}
 		return errors.WithStack(err)
 	}
 
@@ -81,6 +94,10 @@ func (router *Router) Exec(method route.Method, path string, bufnr nvim.Buffer)
 		switch raw {
 		case domain.ErrNotFound:
 			return route.NewErrNotFound(bufferPath)
+		case domain.ErrBusy:
+			return route.NewErrBusy()
+		case domain.ErrConflict:
+			return route.NewErrConflict(bufferPath)
 		}
 		switch raw.(type) {
 		case model.ErrValidation:
@@ -103,7 +120,29 @@ func (router *Router) exec(req route.Request, bufnr nvim.Buffer) error {
 		case route.TasksOne.Path:
 			return router.Root.TaskCmd(bufnr).ShowOne(params.TaskID())
 		case route.TasksList.Path:
-			return router.Root.TaskCmd(bufnr).List()
+			return router.Root.TaskCmd(bufnr).List(1, req.Query)
+		case route.TasksListPage.Path:
+			return router.Root.TaskCmd(bufnr).List(params.Page(), req.Query)
+		case route.Agenda.Path:
+			return router.Root.TaskCmd(bufnr).Agenda()
+		case route.Calendar.Path:
+			return router.Root.TaskCmd(bufnr).Calendar(params.Year(), params.Month())
+		case route.CalendarDay.Path:
+			return router.Root.TaskCmd(bufnr).CalendarDay(params.Year(), params.Month(), params.Day())
+		case route.Search.Path:
+			return router.Root.TaskCmd(bufnr).Search(req.Query.Words)
+		case route.Backups.Path:
+			return router.Root.BackupCmd(bufnr).List()
+		case route.Log.Path:
+			return router.Root.LogCmd(bufnr).List(1)
+		case route.LogPage.Path:
+			return router.Root.LogCmd(bufnr).List(params.Page())
+		case route.LogOne.Path:
+			return router.Root.LogCmd(bufnr).One(params.EventID())
+		case route.TasksOneLog.Path:
+			return router.Root.LogCmd(bufnr).TaskList(params.TaskID())
+		case route.Trash.Path:
+			return router.Root.TaskCmd(bufnr).Trash()
 		}
 	case route.MethodWrite:
 		switch path {
@@ -113,11 +152,17 @@ func (router *Router) exec(req route.Request, bufnr nvim.Buffer) error {
 			return router.Root.TaskCmd(bufnr).Update(params.TaskID())
 		case route.TasksOneDone.Path:
 			return router.Root.TaskCmd(bufnr).Done(params.TaskID())
+		case route.BackupsOneRestore.Path:
+			return router.Root.BackupCmd(bufnr).Restore(params.BackupID())
+		case route.TrashOneRestore.Path:
+			return router.Root.TaskCmd(bufnr).Restore(params.TaskID())
 		}
 	case route.MethodDelete:
 		switch path {
 		case route.TasksOne.Path:
 			return router.Root.TaskCmd(bufnr).Delete(params.TaskID())
+		case route.TrashOne.Path:
+			return router.Root.TaskCmd(bufnr).Purge(params.TaskID())
 		}
 	}
 
@@ -141,6 +186,8 @@ func (router *Router) BufferLinesEvent(event vimlib.BufferLinesEvent) error {
 		switch raw {
 		case domain.ErrNotFound:
 			return route.NewErrNotFound(path)
+		case domain.ErrBusy:
+			return route.NewErrBusy()
 		}
 		return errors.WithStack(err)
 	}
@@ -0,0 +1,89 @@
+package view
+
+import (
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
+)
+
+// Agenda : tasks grouped by deadline
+func (renderer *BufferRenderer) Agenda(tasks []model.Task, now time.Time) error {
+	sections := make(map[model.AgendaSection][]model.Task)
+	for _, task := range tasks {
+		section, ok := task.AgendaSection(now)
+		if !ok {
+			continue
+		}
+		sections[section] = append(sections[section], task)
+	}
+
+	ordered := []model.Task{}
+	titles := make(map[int]model.AgendaSection)
+	for _, section := range model.AgendaSections() {
+		if len(sections[section]) == 0 {
+			continue
+		}
+		titles[len(ordered)] = section
+		ordered = append(ordered, sections[section]...)
+	}
+
+	tableLines, tableHighlights, err := toLines(ordered, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// insert section titles between table lines
+	lines := [][]byte{tableLines[0]}
+	highlights := []vimlib.Highlight{}
+	lineNumbers := make([]int, len(tableLines))
+	positions := make([]vimlib.Position, len(ordered))
+	for i, line := range tableLines[1:] {
+		if section, ok := titles[i]; ok {
+			title := section.String()
+			highlights = append(highlights, vimlib.Highlight{Group: "Title", Line: len(lines), StartCol: 0, EndCol: len(title)})
+			lines = append(lines, []byte(title))
+		}
+		lineNumbers[i+1] = len(lines)
+		positions[i] = vimlib.Position{Line: len(lines), Col: 0}
+		lines = append(lines, line)
+	}
+	for _, h := range tableHighlights {
+		h.Line = lineNumbers[h.Line]
+		highlights = append(highlights, h)
+	}
+
+	markIDs := make([]int, len(ordered))
+	if err := renderer.Buffer.SetLines(
+		lines,
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("counteria-agenda"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithPositionedExtmarks(markIDs, positions),
+		renderer.Buffer.WithHighlights(highlights),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := vimlib.LineStates{}
+	for i, task := range ordered {
+		path, err := route.TasksOnePath(task.ID())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		states.Add(markIDs[i], path)
+	}
+	if err := renderer.Buffer.SaveLineState(states); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := renderer.Buffer.Open(
+		renderer.Buffer.WithWindowOption("list", false),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
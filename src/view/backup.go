@@ -0,0 +1,74 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/view/component"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
+)
+
+// BackupList :
+func (renderer *BufferRenderer) BackupList(backups []model.Backup) error {
+	table, err := component.NewTable("Time", "Reason", "Size")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, backup := range backups {
+		if err := table.AddLine(
+			backup.At.Format("2006-01-02 15:04:05"),
+			backup.Reason.String(),
+			fmt.Sprintf("%d KB", (backup.Size+1023)/1024),
+		); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	tableLines, tableHighlights, err := table.Lines(
+		table.WithColumnHighlightGroup("TabLineSel"),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	header := fmt.Sprintf("%d backups", len(backups))
+	lines := append([][]byte{[]byte(header)}, tableLines...)
+	highlights := []vimlib.Highlight{{Group: "Title", Line: 0, StartCol: 0, EndCol: len(header)}}
+	for _, h := range tableHighlights {
+		h.Line++
+		highlights = append(highlights, h)
+	}
+
+	markIDs := make([]int, len(backups))
+	if err := renderer.Buffer.SetLines(
+		lines,
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("counteria-backups"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithExtmarks(markIDs, 2),
+		renderer.Buffer.WithHighlights(highlights),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := vimlib.LineStates{}
+	for i, backup := range backups {
+		path, err := route.BackupsOnePath(backup.ID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		states.Add(markIDs[i], path)
+	}
+	if err := renderer.Buffer.SaveLineState(states); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := renderer.Buffer.Open(
+		renderer.Buffer.WithWindowOption("list", false),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
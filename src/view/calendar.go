@@ -0,0 +1,55 @@
+package view
+
+import (
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/view/component"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
+)
+
+// Calendar : a month calendar page
+func (renderer *BufferRenderer) Calendar(year int, month time.Month, occurrences model.Occurrences, now time.Time) error {
+	calendar := component.NewCalendar(year, month, now)
+	calendar.Add(occurrences...)
+	lines, highlights, cells := calendar.Lines()
+
+	markIDs := make([]int, len(cells))
+	positions := make([]vimlib.Position, len(cells))
+	for i, cell := range cells {
+		positions[i] = cell.Position
+	}
+	if err := renderer.Buffer.SetLines(
+		lines,
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("counteria-calendar"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithPositionedExtmarks(markIDs, positions),
+		renderer.Buffer.WithHighlights(highlights),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := vimlib.LineStates{}
+	for i, cell := range cells {
+		path, err := route.CalendarDayPath(year, month, cell.Day)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		states.Add(markIDs[i], path)
+	}
+	if err := renderer.Buffer.SaveLineState(states); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := renderer.Buffer.Open(
+		renderer.Buffer.WithWindowOption("list", false),
+		renderer.Buffer.WithWindowOption("wrap", false),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
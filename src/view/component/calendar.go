@@ -0,0 +1,159 @@
+package component
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+)
+
+const (
+	calendarCellWidth = 12
+	calendarSeparator = "  |  "
+)
+
+// Calendar : month grid
+type Calendar struct {
+	Year  int
+	Month time.Month
+	Now   time.Time
+
+	occurrences map[int]model.Occurrences
+}
+
+// NewCalendar :
+func NewCalendar(year int, month time.Month, now time.Time) *Calendar {
+	return &Calendar{
+		Year:        year,
+		Month:       month,
+		Now:         now,
+		occurrences: make(map[int]model.Occurrences),
+	}
+}
+
+// Add : to the day cell
+func (calendar *Calendar) Add(occurrences ...model.Occurrence) {
+	for _, o := range occurrences {
+		day := o.At.Day()
+		calendar.occurrences[day] = append(calendar.occurrences[day], o)
+	}
+}
+
+// CalendarCell : a day cell position
+type CalendarCell struct {
+	Day int
+	vimlib.Position
+}
+
+// Lines :
+func (calendar *Calendar) Lines() ([][]byte, []vimlib.Highlight, []CalendarCell) {
+	first := time.Date(calendar.Year, calendar.Month, 1, 0, 0, 0, 0, time.Local)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	offset := int(first.Weekday())
+
+	lines := [][]byte{[]byte(first.Format("2006-01"))}
+	highlights := []vimlib.Highlight{{Group: "Title", Line: 0, StartCol: 0, EndCol: len(lines[0])}}
+
+	weekdays := []string{}
+	for _, w := range model.AllWeekdays() {
+		weekdays = append(weekdays, w.String()[:3])
+	}
+	header, cols := calendarLine(weekdays)
+	lines = append(lines, header)
+	for i, w := range weekdays {
+		highlights = append(highlights, vimlib.Highlight{Group: "TabLineSel", Line: 1, StartCol: cols[i], EndCol: cols[i] + len(w)})
+	}
+
+	cells := []CalendarCell{}
+	for weekStart := 1 - offset; weekStart <= lastDay; weekStart += 7 {
+		rowCount := 1
+		for day := weekStart; day < weekStart+7; day++ {
+			if n := len(calendar.occurrences[day]) + 1; n > rowCount {
+				rowCount = n
+			}
+		}
+
+		for row := 0; row < rowCount; row++ {
+			texts := make([]string, 7)
+			groups := make([]string, 7)
+			for i := range texts {
+				day := weekStart + i
+				if day < 1 || lastDay < day {
+					continue
+				}
+				if row == 0 {
+					texts[i] = strconv.Itoa(day)
+					groups[i] = calendar.dayGroup(day)
+					continue
+				}
+				occurrences := calendar.occurrences[day]
+				if row-1 < len(occurrences) {
+					o := occurrences[row-1]
+					texts[i] = runewidth.Truncate(o.Task.Name(), calendarCellWidth, "..")
+					groups[i] = calendar.occurrenceGroup(o)
+				}
+			}
+
+			lineNumber := len(lines)
+			line, cols := calendarLine(texts)
+			lines = append(lines, line)
+			for i, text := range texts {
+				day := weekStart + i
+				if day < 1 || lastDay < day {
+					continue
+				}
+				cells = append(cells, CalendarCell{
+					Day:      day,
+					Position: vimlib.Position{Line: lineNumber, Col: cols[i]},
+				})
+				if groups[i] == "" {
+					continue
+				}
+				highlights = append(highlights, vimlib.Highlight{
+					Group:    groups[i],
+					Line:     lineNumber,
+					StartCol: cols[i],
+					EndCol:   cols[i] + len(text),
+				})
+			}
+		}
+	}
+
+	return lines, highlights, cells
+}
+
+func (calendar *Calendar) dayGroup(day int) string {
+	y, m, d := calendar.Now.Date()
+	if y == calendar.Year && m == calendar.Month && d == day {
+		return "Search"
+	}
+	return ""
+}
+
+func (calendar *Calendar) occurrenceGroup(occurrence model.Occurrence) string {
+	if occurrence.Done {
+		return "Comment"
+	}
+	if occurrence.At.Before(calendar.Now) {
+		return "Todo"
+	}
+	return ""
+}
+
+// returns the line and byte columns of the cells
+func calendarLine(texts []string) ([]byte, []int) {
+	var b strings.Builder
+	cols := make([]int, len(texts))
+	for i, text := range texts {
+		if i != 0 {
+			b.WriteString(calendarSeparator)
+		}
+		cols[i] = b.Len()
+		b.WriteString(runewidth.FillRight(text, calendarCellWidth))
+	}
+	b.WriteString("  |")
+	return []byte(b.String()), cols
+}
@@ -23,6 +23,8 @@ func NewTaskForm(task *model.Task) *TaskFormView {
 
 	return &TaskFormView{
 		TaskName:    task.Name(),
+		TaskNotes:   task.Notes(),
+		TaskVersion: task.Version(),
 		TaskStartAt: task.StartAt(),
 		TaskRuleView: TaskRuleView{
 			RuleType:      rule.Type(),
@@ -58,6 +60,8 @@ func (view PeriodView) Unit() model.PeriodUnit {
 type TaskFormView struct {
 	TaskID      int       `json:"-"`
 	TaskName    string    `json:"name"`
+	TaskNotes   string    `json:"notes"`
+	TaskVersion int       `json:"version"`
 	TaskStartAt time.Time `json:"startAt"`
 	TaskRuleView
 }
@@ -140,6 +144,16 @@ func (view *TaskFormView) Name() string {
 	return view.TaskName
 }
 
+// Notes :
+func (view *TaskFormView) Notes() string {
+	return view.TaskNotes
+}
+
+// Version :
+func (view *TaskFormView) Version() int {
+	return view.TaskVersion
+}
+
 // Rule :
 func (view *TaskFormView) Rule() *model.TaskRule {
 	return &model.TaskRule{
@@ -0,0 +1,57 @@
+package view
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/view/component"
+	"github.com/pkg/errors"
+)
+
+var formVersionLine = regexp.MustCompile(`^(\s*"version":\s*)\d+`)
+
+// Conflict : compare the stored task and the written task in a new tab
+// NOTE: the version in the form is updated to overwrite the stored task on the next write.
+func (renderer *BufferRenderer) Conflict(stored *model.Task, written *model.Task) error {
+	lines, err := renderer.Vim.BufferLines(renderer.Buffer.Bufnr, 0, -1, false)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for i, line := range lines {
+		if !formVersionLine.Match(line) {
+			continue
+		}
+		replaced := formVersionLine.ReplaceAll(line, []byte("${1}"+strconv.Itoa(stored.Version())))
+		if err := renderer.Vim.SetBufferLines(renderer.Buffer.Bufnr, i, i+1, false, [][]byte{replaced}); err != nil {
+			return errors.WithStack(err)
+		}
+		break
+	}
+
+	storedLines, err := component.NewTaskForm(stored).Lines()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	writtenLines, err := component.NewTaskForm(written).Lines()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var unused interface{}
+	name := fmt.Sprintf("task %d", stored.ID())
+	if err := renderer.Vim.Call("counteria#conflict#open", unused, name, toStrings(storedLines), toStrings(writtenLines)); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return renderer.Warn("conflict: the task was changed after opened. write again to overwrite.")
+}
+
+func toStrings(lines [][]byte) []string {
+	strs := make([]string, len(lines))
+	for i, line := range lines {
+		strs[i] = string(line)
+	}
+	return strs
+}
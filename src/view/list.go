@@ -1,6 +1,7 @@
 package view
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/notomo/counteria.nvim/src/domain/model"
@@ -11,7 +12,20 @@ import (
 )
 
 func toLines(tasks []model.Task, now time.Time) ([][]byte, []vimlib.Highlight, error) {
-	table, err := component.NewTable("", "Name", "Done", "Rule", "Remains")
+	// NOTE: the source column only for the aggregated tasks
+	sourced := false
+	for _, task := range tasks {
+		if task.Source() != "" {
+			sourced = true
+			break
+		}
+	}
+	columns := []string{"", "Name", "Done", "Rule", "Remains"}
+	if sourced {
+		columns = append(columns, "Source")
+	}
+
+	table, err := component.NewTable(columns...)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
@@ -26,6 +40,9 @@ func toLines(tasks []model.Task, now time.Time) ([][]byte, []vimlib.Highlight, e
 		deadline := task.Deadline(now)
 		remainingTime := component.RemainingTime{RemainingTime: deadline.RemainingTime()}
 		remaining := remainingTime.String()
+		if missed := len(deadline.Missed()); missed > 0 {
+			remaining = fmt.Sprintf("%s (missed %d)", remaining, missed)
+		}
 
 		status := " "
 		if !remainingTime.Exists() {
@@ -38,8 +55,11 @@ func toLines(tasks []model.Task, now time.Time) ([][]byte, []vimlib.Highlight, e
 			})
 		}
 
-		rule := task.Rule().String()
-		if err := table.AddLine(status, task.Name(), at, rule, remaining); err != nil {
+		cells := []string{status, task.Name(), at, task.Rule().String(), remaining}
+		if sourced {
+			cells = append(cells, task.Source())
+		}
+		if err := table.AddLine(cells...); err != nil {
 			return nil, nil, errors.WithStack(err)
 		}
 	}
@@ -54,19 +74,26 @@ func toLines(tasks []model.Task, now time.Time) ([][]byte, []vimlib.Highlight, e
 }
 
 // TaskList :
-func (renderer *BufferRenderer) TaskList(tasks []model.Task, now time.Time) error {
-	lines, highlights, err := toLines(tasks, now)
+func (renderer *BufferRenderer) TaskList(header string, tasks []model.Task, now time.Time) error {
+	tableLines, tableHighlights, err := toLines(tasks, now)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	lines := append([][]byte{[]byte(header)}, tableLines...)
+	highlights := []vimlib.Highlight{{Group: "Title", Line: 0, StartCol: 0, EndCol: len(header)}}
+	for _, h := range tableHighlights {
+		h.Line++
+		highlights = append(highlights, h)
+	}
+
 	markIDs := make([]int, len(tasks))
 	if err := renderer.Buffer.SetLines(
 		lines,
 		renderer.Buffer.WithBufferType("nofile"),
 		renderer.Buffer.WithFileType("counteria-tasks"),
 		renderer.Buffer.WithModifiable(false),
-		renderer.Buffer.WithExtmarks(markIDs, 1),
+		renderer.Buffer.WithExtmarks(markIDs, 2),
 		renderer.Buffer.WithHighlights(highlights),
 	); err != nil {
 		return errors.WithStack(err)
@@ -0,0 +1,124 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/view/component"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
+)
+
+// EventList :
+func (renderer *BufferRenderer) EventList(header string, events []model.TaskEvent) error {
+	table, err := component.NewTable("Time", "Type", "Task", "Actor")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, event := range events {
+		if err := table.AddLine(
+			event.At.Format("2006-01-02 15:04:05"),
+			event.Type.String(),
+			event.TaskName,
+			event.Actor,
+		); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	tableLines, tableHighlights, err := table.Lines(
+		table.WithColumnHighlightGroup("TabLineSel"),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines := append([][]byte{[]byte(header)}, tableLines...)
+	highlights := []vimlib.Highlight{{Group: "Title", Line: 0, StartCol: 0, EndCol: len(header)}}
+	for _, h := range tableHighlights {
+		h.Line++
+		highlights = append(highlights, h)
+	}
+
+	markIDs := make([]int, len(events))
+	if err := renderer.Buffer.SetLines(
+		lines,
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("counteria-log"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithExtmarks(markIDs, 2),
+		renderer.Buffer.WithHighlights(highlights),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := vimlib.LineStates{}
+	for i, event := range events {
+		path, err := route.LogOnePath(event.ID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		states.Add(markIDs[i], path)
+	}
+	if err := renderer.Buffer.SaveLineState(states); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := renderer.Buffer.Open(
+		renderer.Buffer.WithWindowOption("list", false),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// eventView : null snapshot if the task did not exist
+type eventView struct {
+	ID     int             `json:"id"`
+	TaskID int             `json:"taskId"`
+	Type   string          `json:"type"`
+	At     time.Time       `json:"at"`
+	Actor  string          `json:"actor"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// OneEvent : a read only event page
+func (renderer *BufferRenderer) OneEvent(event *model.TaskEvent) error {
+	view := eventView{
+		ID:     event.ID,
+		TaskID: event.TaskID,
+		Type:   event.Type.String(),
+		At:     event.At,
+		Actor:  event.Actor,
+	}
+	if event.Before != "" {
+		view.Before = json.RawMessage(event.Before)
+	}
+	if event.After != "" {
+		view.After = json.RawMessage(event.After)
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(&view); err != nil {
+		return errors.WithStack(err)
+	}
+	lines := bytes.Split(b.Bytes(), []byte("\n"))
+
+	if err := renderer.Buffer.SetLines(
+		lines[:len(lines)-1],
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("json"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithOpen(),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
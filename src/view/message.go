@@ -1,5 +1,11 @@
 package view
 
+// Info :
+func (renderer *Renderer) Info(msg string) error {
+	var unused interface{}
+	return renderer.Vim.Call("counteria#messenger#info", unused, msg)
+}
+
 // Warn :
 func (renderer *Renderer) Warn(msg string) error {
 	var unused interface{}
@@ -11,3 +17,12 @@ func (renderer *Renderer) Err(msg string) error {
 	var unused interface{}
 	return renderer.Vim.Call("counteria#messenger#error", unused, msg)
 }
+
+// Choose : returns the selected index, or -1 if canceled
+func (renderer *Renderer) Choose(msg string, choices ...string) (int, error) {
+	var result int
+	if err := renderer.Vim.Call("counteria#messenger#confirm", &result, msg, choices); err != nil {
+		return -1, err
+	}
+	return result - 1, nil
+}
@@ -0,0 +1,78 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
+)
+
+// SearchResult : matched tasks with the matched fields
+func (renderer *BufferRenderer) SearchResult(header string, hits []model.SearchHit) error {
+	lines := [][]byte{[]byte(header)}
+	highlights := []vimlib.Highlight{{Group: "Title", Line: 0, StartCol: 0, EndCol: len(header)}}
+	positions := make([]vimlib.Position, len(hits))
+
+	addMatches := func(line int, offset int, field model.SearchField) {
+		for _, m := range field.Matches {
+			highlights = append(highlights, vimlib.Highlight{
+				Group:    "Search",
+				Line:     line,
+				StartCol: offset + m.Start,
+				EndCol:   offset + m.End,
+			})
+		}
+	}
+
+	for i, hit := range hits {
+		positions[i] = vimlib.Position{Line: len(lines), Col: 0}
+		lines = append(lines, []byte(hit.Task.Name()))
+
+		for _, field := range hit.Fields {
+			if field.Name == "name" {
+				addMatches(positions[i].Line, 0, field)
+				continue
+			}
+			// NOTE: keep byte offsets of the matches
+			text := strings.NewReplacer("\r", " ", "\n", " ").Replace(field.Text)
+			prefix := "  " + field.Name + ": "
+			highlights = append(highlights, vimlib.Highlight{Group: "Comment", Line: len(lines), StartCol: 0, EndCol: len(prefix)})
+			addMatches(len(lines), len(prefix), field)
+			lines = append(lines, []byte(prefix+text))
+		}
+	}
+
+	markIDs := make([]int, len(hits))
+	if err := renderer.Buffer.SetLines(
+		lines,
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("counteria-search"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithPositionedExtmarks(markIDs, positions),
+		renderer.Buffer.WithHighlights(highlights),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := vimlib.LineStates{}
+	for i, hit := range hits {
+		path, err := route.TasksOnePath(hit.Task.ID())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		states.Add(markIDs[i], path)
+	}
+	if err := renderer.Buffer.SaveLineState(states); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := renderer.Buffer.Open(
+		renderer.Buffer.WithWindowOption("list", false),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
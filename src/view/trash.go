@@ -0,0 +1,74 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/notomo/counteria.nvim/src/domain/model"
+	"github.com/notomo/counteria.nvim/src/router/route"
+	"github.com/notomo/counteria.nvim/src/view/component"
+	"github.com/notomo/counteria.nvim/src/vimlib"
+	"github.com/pkg/errors"
+)
+
+// Trash :
+func (renderer *BufferRenderer) Trash(trashed []model.TrashedTask) error {
+	table, err := component.NewTable("Deleted", "Name", "Rule")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, task := range trashed {
+		if err := table.AddLine(
+			task.DeletedAt.Format("2006-01-02 15:04:05"),
+			task.Name(),
+			task.Rule().String(),
+		); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	tableLines, tableHighlights, err := table.Lines(
+		table.WithColumnHighlightGroup("TabLineSel"),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	header := fmt.Sprintf("%d trashed tasks", len(trashed))
+	lines := append([][]byte{[]byte(header)}, tableLines...)
+	highlights := []vimlib.Highlight{{Group: "Title", Line: 0, StartCol: 0, EndCol: len(header)}}
+	for _, h := range tableHighlights {
+		h.Line++
+		highlights = append(highlights, h)
+	}
+
+	markIDs := make([]int, len(trashed))
+	if err := renderer.Buffer.SetLines(
+		lines,
+		renderer.Buffer.WithBufferType("nofile"),
+		renderer.Buffer.WithFileType("counteria-trash"),
+		renderer.Buffer.WithModifiable(false),
+		renderer.Buffer.WithExtmarks(markIDs, 2),
+		renderer.Buffer.WithHighlights(highlights),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := vimlib.LineStates{}
+	for i, task := range trashed {
+		path, err := route.TrashOnePath(task.ID())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		states.Add(markIDs[i], path)
+	}
+	if err := renderer.Buffer.SaveLineState(states); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := renderer.Buffer.Open(
+		renderer.Buffer.WithWindowOption("list", false),
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
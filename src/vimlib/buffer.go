@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/neovim/go-client/nvim"
@@ -28,7 +29,7 @@ func (factory *BufferClientFactory) Current() *BufferClient {
 // NOTE: avoid executing BufReadCmd
 func (factory *BufferClientFactory) GetOrCreate(path string) (*BufferClient, error) {
 	var bufnr int
-	pattern := fmt.Sprintf("^%s$", path)
+	pattern := fmt.Sprintf("^%s$", filePatternEscaper.Replace(path))
 	if err := factory.Vim.Call("bufnr", &bufnr, pattern); err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -49,6 +50,8 @@ func (factory *BufferClientFactory) GetOrCreate(path string) (*BufferClient, err
 	return factory.Get(buf), nil
 }
 
+var filePatternEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "{", `\{`)
+
 // Get :
 func (factory *BufferClientFactory) Get(bufnr nvim.Buffer) *BufferClient {
 	factory.getNamespace.Do(func() {
@@ -130,9 +133,10 @@ func (client *BufferClient) LoadLineState() (*LineState, error) {
 		return nil, errors.WithStack(err)
 	}
 
+	// NOTE: use the nearest mark on the left of the cursor
 	line := pos[0] - 1
 	start := []int{line, 0}
-	end := []int{line, -1}
+	end := []int{line, pos[1]}
 	noneOpts := map[string]interface{}{}
 	marks, err := client.Vim.BufferExtmarks(client.Bufnr, client.NsID, start, end, noneOpts)
 	if err != nil {
@@ -142,7 +146,7 @@ func (client *BufferClient) LoadLineState() (*LineState, error) {
 	if len(marks) == 0 {
 		return nil, ErrNoState
 	}
-	id := marks[0].ExtmarkID
+	id := marks[len(marks)-1].ExtmarkID
 
 	state, ok := states[strconv.Itoa(id)]
 	if !ok {
@@ -229,6 +233,22 @@ func (client *BufferClient) WithExtmarks(results []int, startLine int) func(*nvi
 	}
 }
 
+// Position : 0-based line and byte column
+type Position struct {
+	Line int
+	Col  int
+}
+
+// WithPositionedExtmarks :
+func (client *BufferClient) WithPositionedExtmarks(results []int, positions []Position) func(*nvim.Batch) {
+	noneOpts := map[string]interface{}{}
+	return func(batch *nvim.Batch) {
+		for i, pos := range positions {
+			batch.SetBufferExtmark(client.Bufnr, client.NsID, 0, pos.Line, pos.Col, noneOpts, &results[i])
+		}
+	}
+}
+
 // Highlight : color and position
 type Highlight struct {
 	Group    string
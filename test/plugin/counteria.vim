@@ -13,7 +13,7 @@ function! s:suite.do_tasks_new()
     call s:helper.sync_execute('open', 'tasks')
     call s:assert.match_path('counteria://tasks')
 
-    normal! j
+    call s:helper.search('name')
     call s:helper.sync_execute('do', 'done')
 
     call s:helper.sync_execute('do')
@@ -31,7 +31,7 @@ function! s:suite.open_tasks_new()
     call s:assert.match_path('counteria://tasks')
 
     let line = line('$')
-    normal! j
+    call s:helper.search('new_task')
     call s:helper.sync_execute('do', 'delete')
 
     call s:assert.match_path('counteria://tasks')
@@ -51,3 +51,207 @@ function! s:suite.update_task()
 
     call s:helper.search('updated_task')
 endfunction
+
+function! s:suite.update_stale_task()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "stale_task",')
+    call s:helper.sync_write()
+
+    call s:helper.search('version')
+    call s:helper.replace_line('"version": 0,')
+    call s:helper.sync_write()
+
+    call s:assert.equals(tabpagenr('$'), 2)
+    call s:assert.filetype('%', 'json')
+endfunction
+
+function! s:suite.open_calendar()
+    call s:helper.sync_execute('open', 'calendar/2020/1')
+    call s:assert.match_path('counteria://calendar/2020/1')
+    call s:assert.filetype('%', 'counteria-calendar')
+
+    call s:helper.sync_execute('do', 'next')
+    call s:assert.match_path('counteria://calendar/2020/2')
+
+    call s:helper.sync_execute('do', 'prev')
+    call s:helper.sync_execute('do', 'prev')
+    call s:assert.match_path('counteria://calendar/2019/12')
+endfunction
+
+function! s:suite.done_in_agenda()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'agenda')
+    call s:assert.match_path('counteria://agenda')
+    call s:assert.filetype('%', 'counteria-agenda')
+
+    call s:helper.search('^Tomorrow')
+    normal! j
+    call s:helper.sync_execute('do', 'done')
+
+    call s:assert.match_path('counteria://agenda')
+    call s:helper.search('^Done today')
+endfunction
+
+function! s:suite.move_tasks_page()
+    call s:helper.sync_execute('open', 'tasks')
+    call s:helper.search('page 1 of 1')
+
+    call s:helper.sync_execute('do', 'next')
+    call s:assert.match_path('counteria://tasks')
+endfunction
+
+function! s:suite.filter_tasks()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "filtered_task",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'tasks?name=filtered')
+    call s:assert.match_path('counteria://tasks\?name\=filtered')
+    call s:helper.search('filtered_task')
+
+    call s:helper.sync_execute('open', 'tasks?filter=overdue')
+    call s:assert.not_found('filtered_task')
+endfunction
+
+function! s:suite.sort_tasks()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "sorted_task",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'tasks?sort=name&order=desc')
+    call s:assert.match_path('counteria://tasks\?sort\=name\&order\=desc')
+    call s:helper.search('sorted_task')
+endfunction
+
+function! s:suite.search_tasks()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "searched_task",')
+    call s:helper.search('notes')
+    call s:helper.replace_line('"notes": "memo",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'search?q=memo')
+    call s:helper.search('searched_task')
+    call s:helper.search('notes: memo')
+
+    call s:helper.sync_execute('open', 'search?q=nothing')
+    call s:assert.not_found('searched_task')
+endfunction
+
+function! s:suite.export_tasks()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "exported_task",')
+    call s:helper.sync_write()
+
+    let path = 'test/_test_data/export.json'
+    call s:helper.sync_execute('export', path)
+
+    let doc = json_decode(join(readfile(path), ''))
+    call s:assert.equals(doc.version, 1)
+    call s:assert.equals(doc.tasks[0].name, 'exported_task')
+endfunction
+
+function! s:suite.export_calendar()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "calendar_task",')
+    call s:helper.sync_write()
+
+    let path = 'test/_test_data/tasks.ics'
+    call s:helper.sync_execute('export-calendar', path)
+
+    let lines = readfile(path)
+    call s:assert.equals(lines[0], "BEGIN:VCALENDAR\r")
+    call s:assert.not_equals(index(lines, "SUMMARY:calendar_task\r"), -1)
+endfunction
+
+function! s:suite.export_history()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "history_task",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'tasks')
+    call s:helper.search('history_task')
+    call s:helper.sync_execute('do', 'done')
+
+    let path = 'test/_test_data/history.csv'
+    call s:helper.sync_execute('export-history', path, 'from=2000-01-01')
+
+    let lines = readfile(path)
+    call s:assert.equals(lines[0], 'task_id,task_name,done_name,done_at,deadline,lateness_seconds')
+    call s:assert.match(lines[1], 'history_task,history_task,')
+endfunction
+
+function! s:suite.open_backups()
+    call s:helper.require_sqlite()
+
+    call s:helper.sync_execute('restore')
+    call s:assert.match_path('counteria://backups')
+    call s:helper.search('backups')
+endfunction
+
+function! s:suite.open_log()
+    call s:helper.require_sqlite()
+
+    call s:helper.sync_execute('open', 'log')
+    call s:assert.match_path('counteria://log')
+    call s:assert.filetype('%', 'counteria-log')
+    call s:helper.search('log page')
+endfunction
+
+function! s:suite.restore_trashed_task()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "trashed_task",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'tasks')
+    call s:helper.search('trashed_task')
+    call s:helper.sync_execute('do', 'delete')
+
+    call s:helper.sync_execute('open', 'trash')
+    call s:assert.match_path('counteria://trash')
+    call s:helper.search('trashed_task')
+    call s:helper.sync_execute('do', 'restore')
+    call s:assert.match_path('counteria://trash')
+
+    call s:helper.sync_execute('open', 'tasks')
+    call s:helper.search('trashed_task')
+endfunction
+
+function! s:suite.switch_profile()
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "default_profile_task",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('profile', 'work')
+    call s:assert.match_path('counteria://tasks')
+    call s:helper.search('\[work\] page 1 of 1')
+    call s:assert.not_found('default_profile_task')
+
+    call s:helper.sync_execute('profile', 'default')
+    call s:helper.search('\[default\] page 1 of 1')
+    call s:helper.search('default_profile_task')
+endfunction
+
+function! s:suite.list_attached_tasks()
+    let g:counteria_attach = {'team': 'test/_test_data/team.db'}
+
+    call s:helper.sync_read('counteria://tasks/new')
+    call s:helper.search('name')
+    call s:helper.replace_line('"name": "sourced_task",')
+    call s:helper.sync_write()
+
+    call s:helper.sync_execute('open', 'tasks')
+    call s:helper.search('Source')
+    call s:helper.search('sourced_task.*default')
+endfunction